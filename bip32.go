@@ -0,0 +1,302 @@
+package gokey
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	_ "embed"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/ripemd160"
+	"golang.org/x/text/unicode/norm"
+)
+
+// below code implements BIP39 mnemonics, BIP32 hierarchical deterministic keys
+// and BIP44 paths as described in https://github.com/bitcoin/bips
+
+//go:embed wordlists/bip39-english.txt
+var bip39English string
+
+var bip39Words = strings.Fields(bip39English)
+
+// HardenedKey is added to the index of hardened child keys
+const HardenedKey = 0x80000000
+
+// extended key version bytes for mainnet
+const (
+	xprvVersion = 0x0488ade4
+	xpubVersion = 0x0488b21e
+)
+
+// GetMnemonic derives the BIP39 mnemonic of 12 or 24 English words for the
+// realm
+func GetMnemonic(password, realm string, seed []byte, words int, allowUnsafe bool) (string, error) {
+	if words != 12 && words != 24 {
+		return "", errors.New("BIP39 mnemonic should have 12 or 24 words")
+	}
+
+	rng, err := getReader(password, realm+"-bip39", seed, allowUnsafe)
+	if err != nil {
+		return "", err
+	}
+
+	entropy := make([]byte, words*4/3)
+	_, err = io.ReadFull(rng, entropy)
+	if err != nil {
+		return "", err
+	}
+
+	return entropyToMnemonic(entropy), nil
+}
+
+// entropyToMnemonic splits the entropy followed by the checksum (first
+// entropy bits / 32 bits of its SHA-256) into 11-bit word indices
+func entropyToMnemonic(entropy []byte) string {
+	checksum := sha256.Sum256(entropy)
+	bits := new(big.Int).SetBytes(entropy)
+	checksumBits := uint(len(entropy) / 4)
+	bits.Lsh(bits, checksumBits)
+	bits.Or(bits, big.NewInt(int64(checksum[0]>>(8-checksumBits))))
+
+	words := make([]string, (len(entropy)*8+int(checksumBits))/11)
+	index := new(big.Int)
+	mask := big.NewInt(2047)
+	for i := len(words) - 1; i >= 0; i-- {
+		index.And(bits, mask)
+		words[i] = bip39Words[index.Int64()]
+		bits.Rsh(bits, 11)
+	}
+
+	return strings.Join(words, " ")
+}
+
+// MnemonicToSeed validates the BIP39 mnemonic and returns the 64-byte BIP32
+// seed for the optional passphrase
+func MnemonicToSeed(mnemonic, passphrase string) ([]byte, error) {
+	words := strings.Fields(mnemonic)
+	if len(words) == 0 || len(words)%3 != 0 || len(words) > 24 {
+		return nil, errors.New("invalid BIP39 mnemonic length")
+	}
+
+	bits := new(big.Int)
+	for _, word := range words {
+		index := -1
+		for i, w := range bip39Words {
+			if w == word {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return nil, fmt.Errorf("unknown BIP39 word %q", word)
+		}
+
+		bits.Lsh(bits, 11)
+		bits.Or(bits, big.NewInt(int64(index)))
+	}
+
+	checksumBits := uint(len(words) / 3)
+	entropy := make([]byte, len(words)*4/3)
+	new(big.Int).Rsh(bits, checksumBits).FillBytes(entropy)
+	if entropyToMnemonic(entropy) != strings.Join(words, " ") {
+		return nil, errors.New("invalid BIP39 mnemonic checksum")
+	}
+
+	mnemonic = norm.NFKD.String(strings.Join(words, " "))
+	salt := norm.NFKD.String("mnemonic" + passphrase)
+	return pbkdf2.Key([]byte(mnemonic), []byte(salt), 2048, 64, sha512.New), nil
+}
+
+// ExtendedKey is a BIP32 extended private key
+type ExtendedKey struct {
+	Depth             byte
+	ParentFingerprint [4]byte
+	ChildNumber       uint32
+	ChainCode         []byte
+	Key               *big.Int
+}
+
+// NewMasterKey returns the BIP32 master key of the seed
+func NewMasterKey(seed []byte) (*ExtendedKey, error) {
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+
+	key := new(big.Int).SetBytes(sum[:32])
+	if key.Sign() == 0 || key.Cmp(secp256k1.S256().Params().N) >= 0 {
+		return nil, errors.New("invalid BIP32 master key")
+	}
+
+	return &ExtendedKey{ChainCode: sum[32:], Key: key}, nil
+}
+
+// GetMasterKey derives the BIP39 mnemonic for the realm and returns the BIP32
+// master key of it
+func GetMasterKey(password, realm string, seed []byte, words int, passphrase string, allowUnsafe bool) (*ExtendedKey, error) {
+	mnemonic, err := GetMnemonic(password, realm, seed, words, allowUnsafe)
+	if err != nil {
+		return nil, err
+	}
+
+	bip32Seed, err := MnemonicToSeed(mnemonic, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewMasterKey(bip32Seed)
+}
+
+func (k *ExtendedKey) secp256k1Key() *secp256k1.PrivateKey {
+	return secp256k1.PrivKeyFromBytes(k.Key.FillBytes(make([]byte, 32)))
+}
+
+// PublicKey returns the compressed secp256k1 public key
+func (k *ExtendedKey) PublicKey() []byte {
+	return k.secp256k1Key().PubKey().SerializeCompressed()
+}
+
+// PrivateKey returns the secp256k1 private key
+func (k *ExtendedKey) PrivateKey() *ecdsa.PrivateKey {
+	return k.secp256k1Key().ToECDSA()
+}
+
+func hash160(data []byte) []byte {
+	sha := sha256.Sum256(data)
+	h := ripemd160.New()
+	h.Write(sha[:])
+	return h.Sum(nil)
+}
+
+// Child returns the child key with the index, use HardenedKey + i for
+// hardened keys
+func (k *ExtendedKey) Child(index uint32) (*ExtendedKey, error) {
+	// the depth is serialized as a single byte
+	if k.Depth == math.MaxUint8 {
+		return nil, errors.New("BIP32 path is deeper than 255 levels")
+	}
+
+	var data []byte
+	if index >= HardenedKey {
+		data = append([]byte{0}, k.Key.FillBytes(make([]byte, 32))...)
+	} else {
+		data = k.PublicKey()
+	}
+	data = append(data, byte(index>>24), byte(index>>16), byte(index>>8), byte(index))
+
+	mac := hmac.New(sha512.New, k.ChainCode)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+
+	n := secp256k1.S256().Params().N
+	il := new(big.Int).SetBytes(sum[:32])
+	if il.Cmp(n) >= 0 {
+		return nil, errors.New("invalid BIP32 child key")
+	}
+
+	key := il.Add(il, k.Key)
+	key.Mod(key, n)
+	if key.Sign() == 0 {
+		return nil, errors.New("invalid BIP32 child key")
+	}
+
+	child := &ExtendedKey{Depth: k.Depth + 1, ChildNumber: index, ChainCode: sum[32:], Key: key}
+	copy(child.ParentFingerprint[:], hash160(k.PublicKey()))
+	return child, nil
+}
+
+// Derive walks the derivation path, for example m/44'/0'/0'/0/0 (h can be
+// used instead of ' for hardened keys)
+func (k *ExtendedKey) Derive(path string) (*ExtendedKey, error) {
+	elements := strings.Split(path, "/")
+	if elements[0] != "m" {
+		return nil, fmt.Errorf("invalid BIP32 path %q", path)
+	}
+
+	key := k
+	for _, element := range elements[1:] {
+		var offset uint32
+		if strings.HasSuffix(element, "'") || strings.HasSuffix(element, "h") || strings.HasSuffix(element, "H") {
+			offset = HardenedKey
+			element = element[:len(element)-1]
+		}
+
+		index, err := strconv.ParseUint(element, 10, 31)
+		if err != nil {
+			return nil, fmt.Errorf("invalid BIP32 path %q", path)
+		}
+
+		key, err = key.Child(uint32(index) + offset)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return key, nil
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// base58Check appends the double SHA-256 checksum and encodes the data with
+// Base58
+func base58Check(data []byte) string {
+	first := sha256.Sum256(data)
+	second := sha256.Sum256(first[:])
+	data = append(append([]byte{}, data...), second[:4]...)
+
+	var encoded []byte
+	n := new(big.Int).SetBytes(data)
+	radix := big.NewInt(58)
+	mod := new(big.Int)
+	for n.Sign() > 0 {
+		n.DivMod(n, radix, mod)
+		encoded = append(encoded, base58Alphabet[mod.Int64()])
+	}
+
+	for _, b := range data {
+		if b != 0 {
+			break
+		}
+		encoded = append(encoded, base58Alphabet[0])
+	}
+
+	return string(reverse(encoded))
+}
+
+func (k *ExtendedKey) serialize(version uint32, key []byte) string {
+	var b bytes.Buffer
+	binary.Write(&b, binary.BigEndian, version)
+	b.WriteByte(k.Depth)
+	b.Write(k.ParentFingerprint[:])
+	binary.Write(&b, binary.BigEndian, k.ChildNumber)
+	b.Write(k.ChainCode)
+	b.Write(key)
+
+	return base58Check(b.Bytes())
+}
+
+// String returns the xprv serialization of the key
+func (k *ExtendedKey) String() string {
+	return k.serialize(xprvVersion, append([]byte{0}, k.Key.FillBytes(make([]byte, 32))...))
+}
+
+// PublicString returns the xpub serialization of the key
+func (k *ExtendedKey) PublicString() string {
+	return k.serialize(xpubVersion, k.PublicKey())
+}
+
+// WIF returns the private key in the wallet import format for a compressed
+// mainnet public key
+func (k *ExtendedKey) WIF() string {
+	return base58Check(append(append([]byte{0x80}, k.Key.FillBytes(make([]byte, 32))...), 1))
+}
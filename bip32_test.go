@@ -0,0 +1,145 @@
+package gokey
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestBIP39Vectors(t *testing.T) {
+	// https://github.com/trezor/python-mnemonic/blob/master/vectors.json
+	for _, test := range []struct {
+		entropy  string
+		mnemonic string
+		seed     string
+	}{
+		{
+			"00000000000000000000000000000000",
+			"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+			"c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+		},
+		{
+			"7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f",
+			"legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth title",
+			"bc09fca1804f7e69da93c2f2028eb238c227f2e9dda30cd63699232578480a4021b146ad717fbb7e451ce9eb835f43620bf5c514db0f8add49f5d121449d3e87",
+		},
+	} {
+		entropy, _ := hex.DecodeString(test.entropy)
+		if mnemonic := entropyToMnemonic(entropy); mnemonic != test.mnemonic {
+			t.Fatalf("invalid mnemonic %v", mnemonic)
+		}
+
+		seed, err := MnemonicToSeed(test.mnemonic, "TREZOR")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if hex.EncodeToString(seed) != test.seed {
+			t.Fatalf("invalid seed for mnemonic %v", test.mnemonic)
+		}
+	}
+
+	_, err := MnemonicToSeed("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon", "")
+	if err == nil {
+		t.Fatal("allowed mnemonic with invalid checksum")
+	}
+}
+
+func TestBIP32Vectors(t *testing.T) {
+	// test vector 1 from BIP32
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	master, err := NewMasterKey(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		path string
+		xprv string
+		xpub string
+	}{
+		{
+			"m",
+			"xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi",
+			"xpub661MyMwAqRbcFtXgS5sYJABqqG9YLmC4Q1Rdap9gSE8NqtwybGhePY2gZ29ESFjqJoCu1Rupje8YtGqsefD265TMg7usUDFdp6W1EGMcet8",
+		},
+		{
+			"m/0'",
+			"xprv9uHRZZhk6KAJC1avXpDAp4MDc3sQKNxDiPvvkX8Br5ngLNv1TxvUxt4cV1rGL5hj6KCesnDYUhd7oWgT11eZG7XnxHrnYeSvkzY7d2bhkJ7",
+			"xpub68Gmy5EdvgibQVfPdqkBBCHxA5htiqg55crXYuXoQRKfDBFA1WEjWgP6LHhwBZeNK1VTsfTFUHCdrfp1bgwQ9xv5ski8PX9rL2dZXvgGDnw",
+		},
+		{
+			"m/0h/1/2h/2/1000000000",
+			"xprvA41z7zogVVwxVSgdKUHDy1SKmdb533PjDz7J6N6mV6uS3ze1ai8FHa8kmHScGpWmj4WggLyQjgPie1rFSruoUihUZREPSL39UNdE3BBDu76",
+			"xpub6H1LXWLaKsWFhvm6RVpEL9P4KfRZSW7abD2ttkWP3SSQvnyA8FSVqNTEcYFgJS2UaFcxupHiYkro49S8yGasTvXEYBVPamhGW6cFJodrTHy",
+		},
+	} {
+		key, err := master.Derive(test.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if key.String() != test.xprv || key.PublicString() != test.xpub {
+			t.Fatalf("invalid extended keys for path %v", test.path)
+		}
+	}
+
+	for _, path := range []string{"", "0/1", "m/x", "m/2147483648"} {
+		_, err = master.Derive(path)
+		if err == nil {
+			t.Fatalf("allowed invalid path %q", path)
+		}
+	}
+
+	// the depth is serialized as a single byte
+	key, err := master.Derive("m" + strings.Repeat("/0'", 255))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if key.Depth != 255 {
+		t.Fatalf("invalid depth %v", key.Depth)
+	}
+
+	_, err = key.Child(0)
+	if err == nil {
+		t.Fatal("allowed path deeper than 255 levels")
+	}
+}
+
+func TestGetMasterKey(t *testing.T) {
+	for _, words := range []int{12, 24} {
+		mnemonic, err := GetMnemonic("pass1", "wallet", nil, words, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(strings.Fields(mnemonic)) != words {
+			t.Fatalf("invalid number of words in %v", mnemonic)
+		}
+
+		_, err = MnemonicToSeed(mnemonic, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err := GetMnemonic("pass1", "wallet", nil, 12, false)
+	if err == nil {
+		t.Fatal("allowed unsafe mnemonic generation")
+	}
+
+	key1, err := GetMasterKey("pass1", "wallet", nil, 24, "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key2, err := GetMasterKey("pass1", "wallet", nil, 24, "extra", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if key1.String() == key2.String() {
+		t.Fatal("master keys match for different passphrases")
+	}
+}
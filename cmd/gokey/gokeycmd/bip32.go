@@ -0,0 +1,52 @@
+package gokeycmd
+
+import (
+	"encoding/hex"
+	"io"
+	"log"
+
+	"github.com/cloudflare/gokey"
+)
+
+func genMnemonic(seed []byte, w io.Writer) {
+	mnemonic, err := gokey.GetMnemonic(pass, realm, seed, words, unsafe)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	_, err = io.WriteString(w, mnemonic+"\n")
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
+
+func genExtendedKey(seed []byte, w io.Writer) {
+	master, err := gokey.GetMasterKey(pass, realm, seed, words, bip39Passphrase, unsafe)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	key, err := master.Derive(path)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	var result string
+	switch {
+	case format == "wif":
+		result = key.WIF()
+	case format == "hex" && public:
+		result = hex.EncodeToString(key.PublicKey())
+	case format == "hex":
+		result = hex.EncodeToString(key.Key.FillBytes(make([]byte, 32)))
+	case public:
+		result = key.PublicString()
+	default:
+		result = key.String()
+	}
+
+	_, err = io.WriteString(w, result+"\n")
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
@@ -0,0 +1,83 @@
+package gokeycmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/cloudflare/gokey"
+)
+
+var dnssecAlgorithms = map[string]gokey.KeyType{
+	"ECDSAP256SHA256": gokey.EC256,
+	"ECDSAP384SHA384": gokey.EC384,
+	"ED25519":         gokey.ED25519,
+	"RSASHA256":       gokey.RSA2048,
+}
+
+func writeBINDFiles(key *gokey.DNSSECKey, dir string) {
+	name, err := key.FileName()
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	var public, private bytes.Buffer
+	err = gokey.EncodePublicToBIND(key, &public)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	err = gokey.EncodeToBIND(key, &private)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	err = ioutil.WriteFile(filepath.Join(dir, name+".key"), public.Bytes(), 0644)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	err = ioutil.WriteFile(filepath.Join(dir, name+".private"), private.Bytes(), 0600)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	fmt.Fprintln(os.Stderr, filepath.Join(dir, name))
+}
+
+func genDNSSEC(seed []byte, w io.Writer) {
+	ksk, zsk, err := gokey.GetDNSSECKeys(pass, realm, seed, dnssecAlgorithms[alg], unsafe)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	dir := output
+	if dir == "" {
+		dir = "."
+	}
+
+	err = os.MkdirAll(dir, 0700)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	writeBINDFiles(ksk, dir)
+	writeBINDFiles(zsk, dir)
+
+	// DS records for the parent zone
+	for _, digestType := range []uint8{gokey.DSSHA256, gokey.DSSHA384} {
+		ds, err := ksk.DS(digestType)
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		_, err = io.WriteString(w, ds+"\n")
+		if err != nil {
+			log.Fatalln(err)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package gokeycmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/cloudflare/gokey"
+)
+
+// pronounceableEntropy is reported by the pronounceable password generator
+var pronounceableEntropy float64
+
+// passwordTypes are the output types, which can be generated without a seed
+var passwordTypes = map[string]bool{
+	"pass":          true,
+	"passphrase":    true,
+	"pronounceable": true,
+	"pin":           true,
+	"keytab":        true,
+}
+
+type entropyReport struct {
+	Type          string  `json:"type"`
+	Entropy       float64 `json:"entropy"`
+	SecretEntropy float64 `json:"secret_entropy"`
+	Source        string  `json:"source"`
+	SourceEntropy float64 `json:"source_entropy"`
+	Warning       string  `json:"warning,omitempty"`
+}
+
+// masterEntropy estimates the entropy of the master password by the number of
+// guesses needed to find it. The strength is estimated with the realm as typed
+// by the user, before the rotation counter and the epoch are appended to it.
+func masterEntropy(strength *gokey.PasswordStrength) float64 {
+	return math.Min(strength.Entropy(), gokey.DRNGEntropy)
+}
+
+// secretEntropy returns the entropy of the generated secret in bits regardless
+// of the master password or the seed it is derived from
+func secretEntropy() float64 {
+	switch keyType {
+	case "pass":
+		if regex != "" {
+			entropy, err := gokey.RegexpEntropy(regex)
+			if err != nil {
+				return 0
+			}
+			return entropy
+		}
+		return passwordSpec().Entropy()
+	case "keytab":
+		return passwordSpec().Entropy()
+	case "passphrase":
+		return passphraseSpec().Entropy()
+	case "pronounceable":
+		return pronounceableEntropy
+	case "pin":
+		return gokey.PINEntropy(length)
+	case "raw", "ecdh":
+		return math.Min(float64(length*8), gokey.DRNGEntropy)
+	case "totp", "hotp":
+		return math.Min(float64(otpAlgorithms[alg].Size()*8), gokey.DRNGEntropy)
+	case "bip39", "bip32":
+		// every word encodes 11 bits and every 33rd bit is the checksum
+		return float64(words * 32 / 3)
+	case "x25519", "wireguard":
+		// clamping fixes 5 bits of the private key
+		return 251
+	}
+
+	// other private keys have at least as much entropy as the DRNG
+	return gokey.DRNGEntropy
+}
+
+// reportEntropy prints the estimated entropy of the output in the requested
+// format and warns, if a key is derived without a seed
+func reportEntropy(seed []byte, strength *gokey.PasswordStrength) {
+	// estimating the entropy may be slow, so it is only done, when it is
+	// requested or needed for the warning
+	unsafeKey := seed == nil && !passwordTypes[keyType]
+	if entropyFormat == "" && !unsafeKey {
+		return
+	}
+
+	report := entropyReport{Type: keyType, SecretEntropy: secretEntropy(), Source: "seed", SourceEntropy: gokey.DRNGEntropy}
+	if seed == nil {
+		report.Source = "master password"
+		report.SourceEntropy = masterEntropy(strength)
+	}
+	report.Entropy = math.Min(report.SecretEntropy, report.SourceEntropy)
+
+	if unsafeKey && report.SourceEntropy < report.SecretEntropy {
+		report.Warning = fmt.Sprintf("%v output is derived without a seed in unsafe mode, so it is at most as strong as the master password", keyType)
+	}
+
+	switch entropyFormat {
+	case "text":
+		fmt.Fprintf(os.Stderr, "Estimated entropy: %.1f bits", report.Entropy)
+		if report.Entropy < report.SecretEntropy {
+			fmt.Fprintf(os.Stderr, " (limited by the %v, the output itself has %.1f bits)", report.Source, report.SecretEntropy)
+		}
+		fmt.Fprintln(os.Stderr, "")
+	case "json":
+		err := json.NewEncoder(os.Stderr).Encode(report)
+		if err != nil {
+			logFatal("%v", err)
+		}
+		return
+	}
+
+	if report.Warning != "" {
+		fmt.Fprintln(os.Stderr, "Warning:", report.Warning)
+	}
+}
@@ -0,0 +1,67 @@
+package gokeycmd
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"github.com/cloudflare/gokey"
+)
+
+const maxEpochDays = int(math.MaxInt64 / (24 * time.Hour))
+
+// epochTime formats the epoch bounds as dates, unless the epochs start in the
+// middle of a day
+func epochTime(t time.Time) string {
+	if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 {
+		return t.Format("2006-01-02")
+	}
+
+	return t.Format(time.RFC3339)
+}
+
+// applyEpoch combines the realm with the current epoch. With -epoch-window the
+// values for the previous, the current and the next epoch are generated side by
+// side.
+func applyEpoch() {
+	start, err := parseTime(epochStart)
+	if err != nil {
+		logFatal("invalid epoch start: %v", err)
+	}
+
+	// longer periods overflow time.Duration
+	if epochDays > maxEpochDays {
+		logFatal("epoch period should not be longer than %v days", maxEpochDays)
+	}
+
+	spec := &gokey.EpochSpec{Start: start, Period: time.Duration(epochDays) * 24 * time.Hour}
+	err = spec.Validate()
+	if err != nil {
+		logFatal("%v", err)
+	}
+
+	t := time.Now()
+	if at != "" {
+		t, err = parseTime(at)
+		if err != nil {
+			logFatal("invalid time: %v", err)
+		}
+	}
+
+	epoch := spec.Epoch(t)
+	if epochWindow {
+		for i, label := range []string{"previous", "current", "next"} {
+			e := epoch + int64(i) - 1
+			begin, end := spec.Bounds(e)
+			variants = append(variants, variant{
+				label: fmt.Sprintf("%v (%v - %v)", label, epochTime(begin), epochTime(end)),
+				realm: gokey.CounterRealm(gokey.EpochRealm(realm, e), counter),
+			})
+		}
+	}
+
+	begin, end := spec.Bounds(epoch)
+	fmt.Fprintf(os.Stderr, "Using epoch %v from %v to %v\n", epoch, epochTime(begin), epochTime(end))
+	realm = gokey.EpochRealm(realm, epoch)
+}
@@ -0,0 +1,46 @@
+package gokeycmd
+
+import (
+	"io"
+	"log"
+	"strings"
+
+	"github.com/cloudflare/gokey"
+)
+
+var kerberosEncTypes = map[string]int{
+	"aes128-cts-hmac-sha1-96":    gokey.EncTypeAES128SHA1,
+	"aes256-cts-hmac-sha1-96":    gokey.EncTypeAES256SHA1,
+	"aes256-cts-hmac-sha384-192": gokey.EncTypeAES256SHA384,
+}
+
+func genKeytab(seed []byte, w io.Writer) {
+	spec := &gokey.KeytabSpec{Principal: principal, Salt: salt, KVNO: uint32(kvno)}
+	for _, name := range strings.Split(encTypes, ",") {
+		encType, ok := kerberosEncTypes[strings.TrimSpace(name)]
+		if !ok {
+			logFatal("unsupported Kerberos encryption type: %v", name)
+		}
+		spec.EncTypes = append(spec.EncTypes, encType)
+	}
+
+	if created != "" {
+		var err error
+		spec.Timestamp, err = parseTime(created)
+		if err != nil {
+			logFatal("invalid creation time: %v", err)
+		}
+	}
+
+	// same password as "pass" type generates for the realm, so it can be set
+	// for the principal in the KDC
+	password, err := gokey.GetPass(pass, realm, seed, passwordSpec())
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	err = gokey.EncodeToKeytab(password, spec, w)
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
@@ -3,13 +3,18 @@ package gokeycmd
 
 import (
 	"bytes"
+	"encoding/base64"
+	"encoding/hex"
 	"flag"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"log"
+	"math"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/cloudflare/gokey"
 	"golang.org/x/term"
@@ -17,20 +22,91 @@ import (
 
 var (
 	pass, passFile, keyType, seedPath, realm, output string
-	unsafe                                           bool
-	seedSkipCount, length                            int
+	peer, info, format, alg, uid, created            string
+	peersPath, node                                  string
+	signPath, verifyPath, sigPath, trustedComment    string
+	principal, salt, encTypes                        string
+	issuer, account, at                              string
+	qrLevel, path, bip39Passphrase                   string
+	separator, wordlistPath                          string
+	specialChars, disable, profilesPath, regex       string
+	alphabet, entropyFormat, epochStart              string
+	unsafe, public, code, qrCode, qrInvert           bool
+	capitalize, withDigit, withSymbol, noAmbiguous   bool
+	rotate, epochWindow, normalize                   bool
+	seedSkipCount, length, pgpVersion, kvno          int
+	digits, period, words                            int
+	minUpper, minLower, minDigits, minSpecial        int
+	counter, passVersion, minScore, epochDays        int
+	derivation                                       int
+	hotpCounter                                      uint64
 )
 
 func initFlags() {
 	flag.StringVar(&pass, "p", "", "master password (if not specified, will be asked interactively)")
 	flag.StringVar(&passFile, "P", "", "master password file (if not specified, will be asked interactively)")
-	flag.StringVar(&keyType, "t", "pass", "output type (can be pass, seed, raw, ec256, ec384, ec521, rsa2048, rsa4096, x25519, ed25519)")
+	flag.StringVar(&keyType, "t", "pass", "output type (can be pass, passphrase, pronounceable, pin, seed, raw, ec256, ec384, ec521, rsa2048, rsa4096, x25519, ed25519, ecdh, openpgp, wireguard, tor-onion, minisign, signify, dnssec, keytab, totp, hotp, bip39, bip32)")
 	flag.StringVar(&seedPath, "s", "", "path to master seed file (optional)")
 	flag.IntVar(&seedSkipCount, "skip", 0, "number of bytes to skip from master seed file (default 0)")
 	flag.StringVar(&realm, "r", "", "password/key realm (most probably purpose of the password/key)")
-	flag.StringVar(&output, "o", "", "output path to store generated key/password (default stdout)")
+	flag.BoolVar(&normalize, "normalize", false, "reduce the realm URL or host name to the registrable domain, so https://www.Example.com/login becomes example.com")
+	flag.IntVar(&counter, "c", 0, "rotation counter, every value generates a new password/key for the realm (default 0)")
+	flag.IntVar(&epochDays, "epoch", 0, "rotation period in days, the realm is combined with the current period (0 disables epochs)")
+	flag.StringVar(&epochStart, "epoch-start", "1970-01-01", "beginning of the first rotation period as a date (2006-01-02), RFC 3339 time or UNIX timestamp")
+	flag.BoolVar(&epochWindow, "epoch-window", false, "output the values for the previous, the current and the next rotation period side by side")
+	flag.StringVar(&output, "o", "", `output path to store generated key/password (default stdout) or output directory for "wireguard", "tor-onion" and "dnssec" types`)
 	flag.BoolVar(&unsafe, "u", false, "UNSAFE: allow key generation without a seed")
-	flag.IntVar(&length, "l", 10, `number of characters in the generated password or number of bytes in the generated raw stream (default 10 for "pass" type and 32 for "raw" type)`)
+	flag.IntVar(&length, "l", 10, `number of characters in the generated password or number of bytes in the generated raw stream or shared key (default 10 for "pass" type, 16 for "pronounceable" type, 6 for "pin" type and 32 for "raw" and "ecdh" types)`)
+	flag.IntVar(&minUpper, "min-upper", 3, "minimum number of upper case letters in the generated password (0 disallows them)")
+	flag.IntVar(&minLower, "min-lower", 3, "minimum number of lower case letters in the generated password (0 disallows them)")
+	flag.IntVar(&minDigits, "min-digits", 1, "minimum number of digits in the generated password (0 disallows them)")
+	flag.IntVar(&minSpecial, "min-special", 1, "minimum number of special characters in the generated password (0 disallows them)")
+	flag.StringVar(&specialChars, "special-chars", "", "special characters allowed in the generated password (default all)")
+	flag.StringVar(&disable, "disable", "", "comma separated character classes not allowed in the generated password: upper, lower, digits, special")
+	flag.StringVar(&alphabet, "alphabet", "", "characters the generated password is made of, Unicode is supported (default printable ASCII characters)")
+	flag.BoolVar(&noAmbiguous, "no-ambiguous", false, "exclude characters, which are easy to confuse (0/O, 1/l/I), from the generated password")
+	flag.IntVar(&passVersion, "pass-version", gokey.PasswordV0, "password generation algorithm: 0 (legacy, default) or 1 (constructive, fast for strict policies)")
+	flag.StringVar(&regex, "regex", "", `generate the password matching the regular expression instead of the password policy (for "pass" type)`)
+	flag.IntVar(&derivation, "derivation", gokey.DerivationV0, "derivation mode: 0 (legacy, default), 1 (Unicode NFC normalization of the master password and the realm) or 2 (Unicode NFKC normalization)")
+	flag.StringVar(&profilesPath, "profile", defaultProfilePath(), "path to the profile file with options for realm patterns (empty disables profiles)")
+	flag.IntVar(&minScore, "min-score", 0, "minimum master password strength score from 0 (too guessable) to 4 (very unguessable), weaker master passwords are refused")
+	flag.StringVar(&entropyFormat, "entropy", "", "print the estimated entropy of the output to stderr: text or json")
+	flag.BoolVar(&public, "pub", false, "output the public key instead of the private key")
+	flag.StringVar(&peer, "peer", "", `path to the PEM-encoded peer public key (for "ecdh" type)`)
+	flag.StringVar(&info, "info", "", `HKDF info string to bind the shared key to (for "ecdh" type)`)
+	flag.StringVar(&format, "format", "hex", `output format: hex, base64 or raw for "ecdh" type; extended, wif or hex for "bip32" type`)
+	flag.StringVar(&alg, "alg", "", `key algorithm (for "openpgp" type: ed25519, rsa2048 or rsa4096, default ed25519; for "dnssec" type: ECDSAP256SHA256, ECDSAP384SHA384, ED25519 or RSASHA256, default ECDSAP256SHA256; for "totp" and "hotp" types: SHA1, SHA256 or SHA512, default SHA1)`)
+	flag.StringVar(&uid, "uid", "", `user ID, for example "John Doe <john@example.com>" (for "openpgp" type)`)
+	flag.StringVar(&created, "created", "", `key creation time as a date (2006-01-02), RFC 3339 time or UNIX timestamp (for "openpgp" and "keytab" types)`)
+	flag.IntVar(&pgpVersion, "pgp-version", 4, `OpenPGP key version: 4 or 6 (for "openpgp" type)`)
+	flag.StringVar(&peersPath, "peers", "", `path to the list of peers: one "<name> <address/prefix> [<endpoint host:port>]" per line (for "wireguard" type)`)
+	flag.StringVar(&node, "node", "", `output the configuration of this node only (for "wireguard" type)`)
+	flag.StringVar(&signPath, "sign", "", `path to the file to sign, outputs the signature instead of the key (for "minisign" and "signify" types)`)
+	flag.StringVar(&verifyPath, "verify", "", `path to the file to verify the signature of (for "minisign" and "signify" types)`)
+	flag.StringVar(&sigPath, "sig", "", `path to the signature to verify (for "minisign" and "signify" types, default is the verified file path with ".minisig" or ".sig" appended)`)
+	flag.StringVar(&principal, "principal", "", `service principal name, for example HTTP/www.example.com@EXAMPLE.COM (for "keytab" type)`)
+	flag.StringVar(&salt, "salt", "", `Kerberos salt (for "keytab" type, default is the realm followed by the principal name components)`)
+	flag.StringVar(&encTypes, "enctypes", "aes256-cts-hmac-sha1-96,aes128-cts-hmac-sha1-96,aes256-cts-hmac-sha384-192", `comma separated Kerberos encryption types (for "keytab" type)`)
+	flag.IntVar(&kvno, "kvno", 1, `key version number (for "keytab" type)`)
+	flag.BoolVar(&code, "code", false, `output the one-time password instead of the provisioning URI (for "totp" and "hotp" types)`)
+	flag.StringVar(&at, "at", "", `time of the one-time password (for "totp" type) or of the rotation period (with -epoch) as a date (2006-01-02), RFC 3339 time or UNIX timestamp (default now)`)
+	flag.IntVar(&digits, "digits", 6, `number of digits in the one-time password: 6, 7 or 8 (for "totp" and "hotp" types)`)
+	flag.IntVar(&period, "period", 30, `time step in seconds (for "totp" type)`)
+	flag.Uint64Var(&hotpCounter, "hotp-counter", 0, `counter value (for "hotp" type)`)
+	flag.StringVar(&issuer, "issuer", "", `issuer in the provisioning URI (for "totp" and "hotp" types)`)
+	flag.StringVar(&account, "account", "", `account name in the provisioning URI (for "totp" and "hotp" types, default is the realm)`)
+	flag.IntVar(&words, "words", 0, `number of words in the BIP39 mnemonic: 12 or 24 (for "bip39" and "bip32" types, default 24) or in the passphrase (for "passphrase" type, default 6)`)
+	flag.StringVar(&separator, "sep", " ", `word separator (for "passphrase" type)`)
+	flag.BoolVar(&capitalize, "capitalize", false, `capitalize every word (for "passphrase" type)`)
+	flag.BoolVar(&withDigit, "digit", false, `append a digit to a random word (for "passphrase" type)`)
+	flag.BoolVar(&withSymbol, "symbol", false, `append a symbol to a random word (for "passphrase" type)`)
+	flag.StringVar(&wordlistPath, "wordlist", "", `path to a wordlist with one word per line, optionally prefixed with dice rolls (for "passphrase" type, default is the EFF large wordlist)`)
+	flag.StringVar(&bip39Passphrase, "bip39-passphrase", "", `optional BIP39 passphrase (for "bip32" type)`)
+	flag.StringVar(&path, "path", "m", `BIP32 derivation path, for example m/44'/0'/0' (for "bip32" type)`)
+	flag.BoolVar(&qrCode, "qr", false, "render the output as a QR code: text for the terminal or PNG or SVG image, if the output path ends with .png or .svg")
+	flag.StringVar(&qrLevel, "qr-level", "M", "QR code error correction level: L, M, Q or H")
+	flag.BoolVar(&qrInvert, "qr-invert", false, "invert the QR code text for terminals with light background")
+	flag.StringVar(&trustedComment, "comment", "", `trusted comment of the signature (for "minisign" type, default includes the timestamp and the file name)`)
 }
 
 var keyTypes = map[string]gokey.KeyType{
@@ -55,8 +131,54 @@ func genSeed(w io.Writer) {
 	}
 }
 
+func passwordSpec() *gokey.PasswordSpec {
+	spec := &gokey.PasswordSpec{Length: length, Upper: minUpper, Lower: minLower, Digits: minDigits, Special: minSpecial, AllowedSpecial: specialChars, Version: passVersion, Alphabet: alphabet, ExcludeAmbiguous: noAmbiguous}
+
+	classes := map[string]*int{"upper": &spec.Upper, "lower": &spec.Lower, "digits": &spec.Digits, "special": &spec.Special}
+	if disable != "" {
+		for _, class := range strings.Split(disable, ",") {
+			count, ok := classes[strings.TrimSpace(class)]
+			if !ok {
+				logFatal("unknown character class: %v", class)
+			}
+			if isFlagSet("min-"+strings.TrimSpace(class)) && *count > 0 {
+				logFatal("character class %v is both required and disabled", class)
+			}
+			*count = 0
+		}
+	}
+
+	err := spec.Validate()
+	if err != nil {
+		logFatal("invalid password policy: %v", err)
+	}
+
+	return spec
+}
+
 func genPass(seed []byte, w io.Writer) {
-	password, err := gokey.GetPass(pass, realm, seed, &gokey.PasswordSpec{length, 3, 3, 1, 1, ""})
+	var password string
+	var err error
+	if regex != "" {
+		password, err = gokey.GetRegexpPass(pass, realm, seed, regex)
+	} else {
+		password, err = gokey.GetPass(pass, realm, seed, passwordSpec())
+	}
+	if err == gokey.ErrPasswordSpecTooStrict {
+		log.Fatalln("password policy is too strict for the legacy algorithm, use -pass-version 1")
+	}
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	_, err = io.WriteString(w, password)
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
+
+func genPronounceable(seed []byte, w io.Writer) {
+	password, entropy, err := gokey.GetPronounceable(pass, realm, seed, passwordSpec())
 	if err != nil {
 		log.Fatalln(err)
 	}
@@ -65,6 +187,50 @@ func genPass(seed []byte, w io.Writer) {
 	if err != nil {
 		log.Fatalln(err)
 	}
+
+	pronounceableEntropy = entropy
+}
+
+func genPIN(seed []byte, w io.Writer) {
+	pin, err := gokey.GetPIN(pass, realm, seed, length)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	_, err = io.WriteString(w, pin)
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
+
+func passphraseSpec() *gokey.PassphraseSpec {
+	spec := &gokey.PassphraseSpec{Words: words, Separator: separator, Capitalize: capitalize, Digit: withDigit, Symbol: withSymbol}
+	if wordlistPath != "" {
+		f, err := os.Open(wordlistPath)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		defer f.Close()
+
+		spec.Wordlist, err = gokey.ParseWordlist(f)
+		if err != nil {
+			log.Fatalln(err)
+		}
+	}
+
+	return spec
+}
+
+func genPassphrase(seed []byte, w io.Writer) {
+	passphrase, err := gokey.GetPassphrase(pass, realm, seed, passphraseSpec())
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	_, err = io.WriteString(w, passphrase)
+	if err != nil {
+		log.Fatalln(err)
+	}
 }
 
 func genKey(seed []byte, w io.Writer) {
@@ -73,7 +239,11 @@ func genKey(seed []byte, w io.Writer) {
 		log.Fatalln(err)
 	}
 
-	err = gokey.EncodeToPem(key, w)
+	if public {
+		err = gokey.EncodePublicToPem(key, w)
+	} else {
+		err = gokey.EncodeToPem(key, w)
+	}
 	if err != nil {
 		log.Fatalln(err)
 	}
@@ -91,14 +261,158 @@ func genRaw(seed []byte, w io.Writer) {
 	}
 }
 
+func genEcdh(seed []byte, w io.Writer) {
+	peerPem, err := ioutil.ReadFile(peer)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	peerKey, err := gokey.DecodePublicPem(peerPem)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	shared, err := gokey.GetSharedKey(pass, realm, seed, peerKey, []byte(info), length, unsafe)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	switch format {
+	case "hex":
+		_, err = io.WriteString(w, hex.EncodeToString(shared))
+	case "base64":
+		_, err = io.WriteString(w, base64.StdEncoding.EncodeToString(shared))
+	default:
+		_, err = w.Write(shared)
+	}
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
+
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
+
+func genOpenPGP(seed []byte, w io.Writer) {
+	creationTime, err := parseTime(created)
+	if err != nil {
+		logFatal("invalid creation time: %v", err)
+	}
+
+	primary, subkey, err := gokey.GetOpenPGPKeys(pass, realm, seed, keyTypes[alg], unsafe)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	spec := &gokey.OpenPGPSpec{UserID: uid, Created: creationTime, Version: pgpVersion}
+	if public {
+		err = gokey.EncodePublicToOpenPGP(primary, subkey, spec, w)
+	} else {
+		err = gokey.EncodeToOpenPGP(primary, subkey, spec, w)
+	}
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
+
+// rotateTypes are the output types supported by the rotate command, which
+// fit into a single line
+var rotateTypes = map[string]bool{
+	"pass":          true,
+	"passphrase":    true,
+	"pronounceable": true,
+	"pin":           true,
+}
+
+// variant is one of the values written side by side by the rotate command and
+// with -epoch-window
+type variant struct {
+	label, realm string
+}
+
+var variants []variant
+
+// generate writes the output of gen or the labelled outputs for all variants
+// side by side
+func generate(gen func([]byte, io.Writer), seed []byte, w io.Writer) {
+	if variants == nil {
+		gen(seed, w)
+		return
+	}
+
+	current := realm
+	for i, v := range variants {
+		var value bytes.Buffer
+		realm = v.realm
+		gen(seed, &value)
+
+		if i > 0 {
+			fmt.Fprintln(w, "")
+		}
+		fmt.Fprintf(w, "%v: %v", v.label, value.String())
+	}
+	realm = current
+}
+
+// commandLine holds the flags given on the command line, options applied from
+// the profile are not included
+var commandLine map[string]bool
+
+// isFlagSet reports, if the flag was given on the command line
 func isFlagSet(name string) bool {
-	found := false
+	return commandLine[name]
+}
+
+// isOptionSet reports, if the option was given on the command line or in the
+// profile, so the default for the output type does not apply
+func isOptionSet(name string) bool {
+	return commandLine[name] || profileOptions[name]
+}
+
+// parseFlags parses the command line arguments and records the flags given on
+// the command line. It returns true, if the output type was given.
+func parseFlags(args []string) (bool, error) {
+	// "gokey rotate [<type>] [options]" outputs the passwords for the current
+	// and the next rotation counter
+	if len(args) > 0 && args[0] == "rotate" {
+		rotate = true
+		args = args[1:]
+	}
+
+	// "gokey <type> [options]" is a shorthand for "gokey -t <type> [options]"
+	typeSet := false
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		keyType = args[0]
+		typeSet = true
+		args = args[1:]
+	}
+
+	err := flag.CommandLine.Parse(args)
+	if err != nil {
+		return false, err
+	}
+
+	commandLine = make(map[string]bool)
 	flag.Visit(func(f *flag.Flag) {
-		if f.Name == name {
-			found = true
-		}
+		commandLine[f.Name] = true
 	})
-	return found
+
+	return typeSet || isFlagSet("t"), nil
+}
+
+// outputIsDir returns true for output types, which write several files into
+// the output directory
+func outputIsDir() bool {
+	return (keyType == "wireguard" && node == "") || keyType == "tor-onion" || keyType == "dnssec"
 }
 
 func logFatal(format string, args ...interface{}) {
@@ -109,9 +423,41 @@ func logFatal(format string, args ...interface{}) {
 
 func Main() {
 	initFlags()
-	flag.Parse()
 
-	var err error
+	typeSet, err := parseFlags(os.Args[1:])
+	if err != nil {
+		logFatal("%v", err)
+	}
+
+	realm, err = gokey.NormalizeInput(realm, derivation)
+	if err != nil {
+		logFatal("%v", err)
+	}
+
+	if realm != "" && normalize {
+		normalized, err := gokey.NormalizeRealm(realm)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		realm = normalized
+		fmt.Fprintf(os.Stderr, "Normalized realm: %v\n", realm)
+	}
+
+	if realm != "" {
+		err = applyProfile(typeSet)
+		if err != nil {
+			log.Fatalln(err)
+		}
+	}
+
+	if minScore < 0 || minScore > 4 {
+		logFatal("minimum master password score should be from 0 to 4")
+	}
+
+	if entropyFormat != "" && entropyFormat != "text" && entropyFormat != "json" {
+		logFatal("unknown entropy format: %v", entropyFormat)
+	}
+
 	if pass == "" && passFile != "" {
 		var content []byte
 		content, err = ioutil.ReadFile(passFile)
@@ -123,6 +469,7 @@ func Main() {
 	if pass == "" {
 		pass = os.Getenv("GOKEY_ROOT_PASS")
 	}
+	interactive := pass == ""
 	if pass == "" {
 		var passBytes []byte
 		var passBytesAgain []byte
@@ -158,8 +505,36 @@ func Main() {
 		pass = string(passBytes)
 	}
 
+	pass, _ = gokey.NormalizeInput(pass, derivation)
+	if derivation == gokey.DerivationV0 {
+		for _, input := range []struct{ name, value string }{{"master password", pass}, {"realm", realm}} {
+			if !gokey.InputNormalized(input.value) {
+				fmt.Fprintf(os.Stderr, "Warning: the %v is not in Unicode NFC form and may produce different passwords and keys, when typed on another system, consider -derivation 1\n", input.name)
+			}
+		}
+	}
+
+	// master passwords based on the realm are weak
+	strength := gokey.EstimateStrength(pass, realm)
+	if interactive {
+		fmt.Fprintf(os.Stderr, "Master password strength: %v of 4", strength.Score)
+		if strength.Warning != "" {
+			fmt.Fprintf(os.Stderr, " (%v)", strength.Warning)
+		}
+		fmt.Fprintln(os.Stderr, "")
+
+		fingerprint, err := gokey.Fingerprint(pass)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		fmt.Fprintf(os.Stderr, "Master password fingerprint: %v\n", fingerprint)
+	}
+	if strength.Score < minScore {
+		log.Fatalf("master password is too weak: score %v is less than %v (%v)\n", strength.Score, minScore, strength.Warning)
+	}
+
 	out := os.Stdout
-	if output != "" {
+	if output != "" && !outputIsDir() {
 		out, err = os.OpenFile(output, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
 		if err != nil {
 			log.Fatalln(err)
@@ -167,12 +542,53 @@ func Main() {
 		defer out.Close()
 	}
 
+	// with -qr the output is collected and rendered as a QR code afterwards
+	var w io.Writer = out
+	var qrData bytes.Buffer
+	if qrCode {
+		if _, ok := qrLevels[qrLevel]; !ok {
+			logFatal("unknown QR error correction level: %v", qrLevel)
+		}
+		if output != "" && outputIsDir() {
+			logFatal("output type %v can not be rendered as a QR code into a directory", keyType)
+		}
+		w = &qrData
+	}
+
 	if keyType == "seed" {
-		genSeed(out)
+		genSeed(w)
 	} else {
 		if realm == "" {
 			logFatal("no realm provided")
 		}
+		// the counter changes the derived values, but not the names in the output
+		if account == "" {
+			account = realm
+		}
+		if counter < 0 {
+			logFatal("invalid rotation counter")
+		}
+		if (counter != 0 || epochDays != 0) && keyType == "dnssec" {
+			logFatal("rotation counter and epochs are not supported for dnssec type, the realm is the zone name")
+		}
+		if (rotate || epochWindow) && (!rotateTypes[keyType] || qrCode) {
+			logFatal("rotate command and -epoch-window support pass, passphrase, pronounceable and pin types without -qr")
+		}
+		if epochWindow && (rotate || epochDays == 0) {
+			logFatal("-epoch-window requires -epoch and can not be used with rotate command")
+		}
+		if at != "" && epochDays == 0 && (!code || keyType != "totp") {
+			logFatal("-at is only supported with -code for totp type or with -epoch")
+		}
+		if epochDays != 0 {
+			applyEpoch()
+		}
+		if rotate {
+			for i, label := range []string{"old", "new"} {
+				variants = append(variants, variant{label: fmt.Sprintf("%v (-c %v)", label, counter+i), realm: gokey.CounterRealm(realm, counter+i)})
+			}
+		}
+		realm = gokey.CounterRealm(realm, counter)
 
 		var seed []byte
 		if seedPath != "" {
@@ -187,21 +603,159 @@ func Main() {
 			seed = seed[seedSkipCount:]
 		}
 
+		if public && keyType != "openpgp" && keyType != "minisign" && keyType != "signify" && keyType != "bip32" {
+			if _, ok := keyTypes[keyType]; !ok {
+				logFatal("output type %v does not have a public key", keyType)
+			}
+		}
+
 		switch keyType {
 		case "pass":
 			if length <= 0 {
 				logFatal("invalid length parameter")
 			}
-			genPass(seed, out)
+			if regex != "" {
+				for _, name := range []string{"l", "min-upper", "min-lower", "min-digits", "min-special", "special-chars", "disable", "pass-version", "alphabet", "no-ambiguous"} {
+					if isFlagSet(name) {
+						logFatal("-%v can not be used with -regex", name)
+					}
+				}
+			}
+			generate(genPass, seed, w)
+			fmt.Fprintln(os.Stderr, "")
+		case "passphrase":
+			if !isOptionSet("words") {
+				words = 6
+			}
+			if words <= 0 {
+				logFatal("invalid words parameter")
+			}
+			generate(genPassphrase, seed, w)
+			fmt.Fprintln(os.Stderr, "")
+		case "pronounceable":
+			if !isOptionSet("l") {
+				length = 16
+			}
+			if !isFlagSet("entropy") {
+				entropyFormat = "text"
+			}
+			generate(genPronounceable, seed, w)
+			fmt.Fprintln(os.Stderr, "")
+		case "pin":
+			if !isOptionSet("l") {
+				length = 6
+			}
+			if length < gokey.MinPINLength || length > gokey.MaxPINLength {
+				logFatal("PIN length should be from %v to %v digits", gokey.MinPINLength, gokey.MaxPINLength)
+			}
+			generate(genPIN, seed, w)
 			fmt.Fprintln(os.Stderr, "")
 		case "raw":
-			if !isFlagSet("l") {
+			if !isOptionSet("l") {
+				length = 32
+			}
+			if length <= 0 {
+				logFatal("invalid length parameter")
+			}
+			genRaw(seed, w)
+		case "ecdh":
+			if peer == "" {
+				logFatal("no peer public key provided")
+			}
+			if format != "hex" && format != "base64" && format != "raw" {
+				logFatal("unknown output format: %v", format)
+			}
+			if !isOptionSet("l") {
 				length = 32
 			}
 			if length <= 0 {
 				logFatal("invalid length parameter")
 			}
-			genRaw(seed, out)
+			genEcdh(seed, w)
+			if format != "raw" {
+				fmt.Fprintln(os.Stderr, "")
+			}
+		case "openpgp":
+			if alg == "" {
+				alg = "ed25519"
+			}
+			if alg != "ed25519" && alg != "rsa2048" && alg != "rsa4096" {
+				logFatal("unsupported OpenPGP key algorithm: %v", alg)
+			}
+			if uid == "" {
+				logFatal("no user ID provided")
+			}
+			if created == "" {
+				logFatal("no key creation time provided")
+			}
+			genOpenPGP(seed, w)
+		case "wireguard":
+			if peersPath == "" {
+				logFatal("no peer list provided")
+			}
+			if node == "" && output == "" {
+				logFatal("no output directory provided")
+			}
+			genWireGuard(seed, w)
+		case "tor-onion":
+			genOnion(seed, w)
+		case "totp", "hotp":
+			if alg == "" {
+				alg = "SHA1"
+			}
+			if _, ok := otpAlgorithms[alg]; !ok {
+				logFatal("unsupported OTP algorithm: %v", alg)
+			}
+			genOTP(seed, w)
+		case "bip39":
+			if !isOptionSet("words") {
+				words = 24
+			}
+			genMnemonic(seed, w)
+		case "bip32":
+			if !isOptionSet("words") {
+				words = 24
+			}
+			if !isFlagSet("format") {
+				format = "extended"
+			}
+			if format != "extended" && format != "wif" && format != "hex" {
+				logFatal("unknown output format: %v", format)
+			}
+			if format == "wif" && public {
+				logFatal("public keys can not be output in wif format")
+			}
+			genExtendedKey(seed, w)
+		case "keytab":
+			if principal == "" {
+				logFatal("no principal provided")
+			}
+			if length <= 0 {
+				logFatal("invalid length parameter")
+			}
+			if kvno < 0 || kvno > math.MaxUint32 {
+				logFatal("invalid kvno parameter")
+			}
+			genKeytab(seed, w)
+		case "dnssec":
+			if alg == "" {
+				alg = "ECDSAP256SHA256"
+			}
+			if _, ok := dnssecAlgorithms[alg]; !ok {
+				logFatal("unsupported DNSSEC algorithm: %v", alg)
+			}
+			genDNSSEC(seed, w)
+		case "minisign", "signify":
+			if signPath != "" && verifyPath != "" {
+				logFatal("only one of -sign and -verify can be provided")
+			}
+			if verifyPath != "" && output != "" {
+				logFatal("-verify does not produce any output")
+			}
+			if trustedComment != "" && keyType != "minisign" {
+				logFatal("output type %v does not support trusted comments", keyType)
+			}
+			genSigning(seed, w)
 		default:
 			if _, ok := keyTypes[keyType]; !ok {
 				logFatal("unknown key type: %v", keyType)
@@ -209,7 +763,16 @@ func Main() {
 			if isFlagSet("l") {
 				logFatal("key type %v does not support length parameter", keyType)
 			}
-			genKey(seed, out)
+			genKey(seed, w)
+		}
+
+		// verifying a signature does not generate any secret
+		if verifyPath == "" {
+			reportEntropy(seed, strength)
 		}
 	}
+
+	if qrCode {
+		writeQR(qrData.Bytes(), out)
+	}
 }
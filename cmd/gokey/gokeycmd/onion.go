@@ -0,0 +1,43 @@
+package gokeycmd
+
+import (
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/cloudflare/gokey"
+)
+
+func genOnion(seed []byte, w io.Writer) {
+	service, err := gokey.GetOnionService(pass, realm, seed, unsafe)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	if output == "" {
+		_, err = io.WriteString(w, service.Hostname+"\n")
+		if err != nil {
+			log.Fatalln(err)
+		}
+		return
+	}
+
+	// same layout as Tor HiddenServiceDir
+	err = os.MkdirAll(output, 0700)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	for name, content := range map[string][]byte{
+		"hs_ed25519_secret_key": service.SecretKey,
+		"hs_ed25519_public_key": service.PublicKey,
+		"hostname":              []byte(service.Hostname + "\n"),
+	} {
+		err = ioutil.WriteFile(filepath.Join(output, name), content, 0600)
+		if err != nil {
+			log.Fatalln(err)
+		}
+	}
+}
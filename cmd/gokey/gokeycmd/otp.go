@@ -0,0 +1,51 @@
+package gokeycmd
+
+import (
+	"crypto"
+	"io"
+	"log"
+	"time"
+
+	"github.com/cloudflare/gokey"
+)
+
+var otpAlgorithms = map[string]crypto.Hash{
+	"SHA1":   crypto.SHA1,
+	"SHA256": crypto.SHA256,
+	"SHA512": crypto.SHA512,
+}
+
+func genOTP(seed []byte, w io.Writer) {
+	spec := &gokey.OTPSpec{Hash: otpAlgorithms[alg], Digits: digits, Period: time.Duration(period) * time.Second}
+	secret, err := gokey.GetOTPSecret(pass, realm, seed, spec, unsafe)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	var result string
+	switch {
+	case code && keyType == "hotp":
+		result, err = gokey.HOTP(secret, hotpCounter, spec)
+	case code:
+		t := time.Now()
+		if at != "" {
+			t, err = parseTime(at)
+			if err != nil {
+				logFatal("invalid time: %v", err)
+			}
+		}
+		result, err = gokey.TOTP(secret, t, spec)
+	case keyType == "hotp":
+		result, err = gokey.OTPAuthURI(secret, issuer, account, &hotpCounter, spec)
+	default:
+		result, err = gokey.OTPAuthURI(secret, issuer, account, nil, spec)
+	}
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	_, err = io.WriteString(w, result+"\n")
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
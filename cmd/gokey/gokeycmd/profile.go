@@ -0,0 +1,164 @@
+package gokeycmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// profile holds the options for realms matching the pattern. Options not
+// present in the profile keep their defaults and options given on the command
+// line override the profile.
+type profile struct {
+	Realm        string  `json:"realm"`
+	Type         *string `json:"type"`
+	Length       *int    `json:"length"`
+	MinUpper     *int    `json:"min_upper"`
+	MinLower     *int    `json:"min_lower"`
+	MinDigits    *int    `json:"min_digits"`
+	MinSpecial   *int    `json:"min_special"`
+	SpecialChars *string `json:"special_chars"`
+	Disable      *string `json:"disable"`
+	Alphabet     *string `json:"alphabet"`
+	NoAmbiguous  *bool   `json:"no_ambiguous"`
+	PassVersion  *int    `json:"pass_version"`
+	Regex        *string `json:"regex"`
+	Counter      *int    `json:"counter"`
+	Epoch        *int    `json:"epoch"`
+	EpochStart   *string `json:"epoch_start"`
+}
+
+type profileFile struct {
+	Profiles []profile `json:"profiles"`
+}
+
+// defaultProfilePath returns ~/.config/gokey/profiles.json or the equivalent
+// for the platform
+func defaultProfilePath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "gokey", "profiles.json")
+}
+
+func readProfiles(profilePath string) ([]profile, error) {
+	content, err := ioutil.ReadFile(profilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var file profileFile
+	err = json.Unmarshal(content, &file)
+	if err != nil {
+		return nil, fmt.Errorf("invalid profile file %v: %v", profilePath, err)
+	}
+
+	for _, p := range file.Profiles {
+		// filepath.Match only reports malformed patterns when they are used
+		_, err = filepath.Match(p.Realm, "")
+		if p.Realm == "" || err != nil {
+			return nil, fmt.Errorf("invalid realm pattern %q in profile file %v", p.Realm, profilePath)
+		}
+	}
+
+	return file.Profiles, nil
+}
+
+// profileOptions holds the options applied from the profile
+var profileOptions map[string]bool
+
+// profileInt sets the variable of the flag to the value from the profile, if
+// the value is present and the flag was not given on the command line
+func profileInt(name string, value, variable *int) {
+	if value != nil && !isFlagSet(name) {
+		*variable = *value
+		profileOptions[name] = true
+	}
+}
+
+func profileString(name string, value, variable *string) {
+	if value != nil && !isFlagSet(name) {
+		*variable = *value
+		profileOptions[name] = true
+	}
+}
+
+func profileBool(name string, value, variable *bool) {
+	if value != nil && !isFlagSet(name) {
+		*variable = *value
+		profileOptions[name] = true
+	}
+}
+
+// matchProfile returns the first profile with the realm pattern matching the
+// realm without regard to case
+func matchProfile(profiles []profile, realm string) *profile {
+	for i := range profiles {
+		if matched, _ := filepath.Match(strings.ToLower(profiles[i].Realm), strings.ToLower(realm)); matched {
+			return &profiles[i]
+		}
+	}
+
+	return nil
+}
+
+// applyProfile sets the options from the first profile matching the realm,
+// which were not given on the command line. The rotation options apply to all
+// output types, the other options only apply to the output type of the profile
+// ("pass" by default), so a password length does not become the length of a
+// PIN or a key.
+func applyProfile(typeSet bool) error {
+	profileOptions = make(map[string]bool)
+	if profilesPath == "" {
+		return nil
+	}
+
+	// the default profile file is optional
+	if _, err := os.Stat(profilesPath); os.IsNotExist(err) && !isFlagSet("profile") {
+		return nil
+	}
+
+	profiles, err := readProfiles(profilesPath)
+	if err != nil {
+		return err
+	}
+
+	p := matchProfile(profiles, realm)
+	if p == nil {
+		return nil
+	}
+
+	profileType := "pass"
+	if p.Type != nil {
+		profileType = *p.Type
+	}
+	if !typeSet {
+		keyType = profileType
+	}
+
+	profileInt("c", p.Counter, &counter)
+	profileInt("epoch", p.Epoch, &epochDays)
+	profileString("epoch-start", p.EpochStart, &epochStart)
+
+	if keyType == profileType {
+		profileInt("l", p.Length, &length)
+		profileInt("min-upper", p.MinUpper, &minUpper)
+		profileInt("min-lower", p.MinLower, &minLower)
+		profileInt("min-digits", p.MinDigits, &minDigits)
+		profileInt("min-special", p.MinSpecial, &minSpecial)
+		profileInt("pass-version", p.PassVersion, &passVersion)
+		profileString("special-chars", p.SpecialChars, &specialChars)
+		profileString("disable", p.Disable, &disable)
+		profileString("alphabet", p.Alphabet, &alphabet)
+		profileString("regex", p.Regex, &regex)
+		profileBool("no-ambiguous", p.NoAmbiguous, &noAmbiguous)
+	}
+
+	fmt.Fprintf(os.Stderr, "Using profile %q from %v\n", p.Realm, profilesPath)
+	return nil
+}
@@ -0,0 +1,109 @@
+package gokeycmd
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testProfiles = `{
+  "profiles": [
+    {"realm": "*.bank.example", "length": 16, "min_digits": 4, "counter": 2},
+    {"realm": "phone", "type": "pin", "length": 8},
+    {"realm": "*.example", "disable": "special"}
+  ]
+}`
+
+// applyTestProfile parses the arguments as the command line and applies the
+// test profiles
+func applyTestProfile(t *testing.T, profiles string, args ...string) error {
+	profilePath := filepath.Join(t.TempDir(), "profiles.json")
+	err := os.WriteFile(profilePath, []byte(profiles), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flag.CommandLine = flag.NewFlagSet("gokey", flag.ContinueOnError)
+	initFlags()
+	typeSet, err := parseFlags(append(args, "-profile", profilePath))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return applyProfile(typeSet)
+}
+
+func TestMatchProfile(t *testing.T) {
+	profiles := []profile{{Realm: "*.bank.example"}, {Realm: "phone"}, {Realm: "*.example"}}
+	for realm, expected := range map[string]string{
+		"www.bank.example": "*.bank.example",
+		"WWW.Bank.Example": "*.bank.example",
+		"shop.example":     "*.example",
+		"phone":            "phone",
+		"example.com":      "",
+	} {
+		p := matchProfile(profiles, realm)
+		if (p == nil && expected != "") || (p != nil && p.Realm != expected) {
+			t.Fatalf("invalid profile for realm %v", realm)
+		}
+	}
+}
+
+func TestProfilePrecedence(t *testing.T) {
+	err := applyTestProfile(t, testProfiles, "-r", "www.bank.example", "-l", "20", "-c", "5")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if length != 20 || counter != 5 || minDigits != 4 || keyType != "pass" {
+		t.Fatalf("command line does not override the profile: length %v, counter %v, min digits %v, type %v", length, counter, minDigits, keyType)
+	}
+
+	if isFlagSet("min-digits") || !isOptionSet("min-digits") {
+		t.Fatal("option from the profile is reported as given on the command line")
+	}
+
+	err = applyTestProfile(t, testProfiles, "-r", "shop.example")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if disable != "special" || length != 10 || counter != 0 {
+		t.Fatal("options of the first matching profile are not applied")
+	}
+
+	err = applyTestProfile(t, "{", "-r", "shop.example")
+	if err == nil {
+		t.Fatal("applied invalid profile file")
+	}
+}
+
+func TestProfileOutputTypes(t *testing.T) {
+	// key types reject -l, so the password length from the profile must not
+	// be applied to them
+	for _, kt := range []string{"ec256", "pin", "raw"} {
+		err := applyTestProfile(t, testProfiles, kt, "-r", "www.bank.example")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if keyType != kt || isOptionSet("l") || length != 10 {
+			t.Fatalf("password length from the profile is applied to %v type", kt)
+		}
+
+		if counter != 2 {
+			t.Fatalf("counter from the profile is not applied to %v type", kt)
+		}
+	}
+
+	// the options apply to the output type of the profile
+	err := applyTestProfile(t, testProfiles, "-r", "phone")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if keyType != "pin" || !isOptionSet("l") || length != 8 {
+		t.Fatal("profile options are not applied to the output type of the profile")
+	}
+}
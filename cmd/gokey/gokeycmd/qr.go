@@ -0,0 +1,43 @@
+package gokeycmd
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/cloudflare/gokey/qr"
+)
+
+var qrLevels = map[string]qr.Level{
+	"L": qr.L,
+	"M": qr.M,
+	"Q": qr.Q,
+	"H": qr.H,
+}
+
+func writeQR(data []byte, w io.Writer) {
+	// trailing newlines of text outputs are not part of the secret
+	data = bytes.TrimRight(data, "\n")
+	if len(data) == 0 {
+		log.Fatalln("no output to render as a QR code")
+	}
+
+	code, err := qr.Encode(data, qrLevels[qrLevel])
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	switch strings.ToLower(filepath.Ext(output)) {
+	case ".png":
+		err = code.WritePNG(w, 8)
+	case ".svg":
+		err = code.WriteSVG(w)
+	default:
+		err = code.WriteText(w, qrInvert)
+	}
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
@@ -0,0 +1,100 @@
+package gokeycmd
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cloudflare/gokey"
+)
+
+// signatureSuffix returns the default signature file extension of the tool
+func signatureSuffix() string {
+	if keyType == "minisign" {
+		return ".minisig"
+	}
+	return ".sig"
+}
+
+func signFile(key *gokey.SigningKey, w io.Writer) {
+	f, err := os.Open(signPath)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer f.Close()
+
+	if keyType == "minisign" {
+		if trustedComment == "" {
+			// same default as minisign
+			trustedComment = fmt.Sprintf("timestamp:%d\tfile:%s\thashed", time.Now().Unix(), filepath.Base(signPath))
+		}
+		err = gokey.MinisignSign(key, f, trustedComment, w)
+	} else {
+		err = gokey.SignifySign(key, f, w)
+	}
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
+
+func verifyFile(key *gokey.SigningKey) {
+	if sigPath == "" {
+		sigPath = verifyPath + signatureSuffix()
+	}
+
+	sig, err := ioutil.ReadFile(sigPath)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	f, err := os.Open(verifyPath)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer f.Close()
+
+	if keyType == "minisign" {
+		var comment string
+		comment, err = gokey.MinisignVerify(key, f, sig)
+		if err == nil {
+			fmt.Fprintf(os.Stderr, "Signature and comment signature verified\nTrusted comment: %s\n", comment)
+		}
+	} else {
+		err = gokey.SignifyVerify(key, f, sig)
+		if err == nil {
+			fmt.Fprintln(os.Stderr, "Signature Verified")
+		}
+	}
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
+
+func genSigning(seed []byte, w io.Writer) {
+	key, err := gokey.GetSigningKey(pass, realm, seed, unsafe)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	switch {
+	case signPath != "":
+		signFile(key, w)
+	case verifyPath != "":
+		verifyFile(key)
+	case keyType == "minisign" && public:
+		err = gokey.EncodePublicToMinisign(key, w)
+	case keyType == "minisign":
+		err = gokey.EncodeToMinisign(key, w)
+	case public:
+		err = gokey.EncodePublicToSignify(key, w)
+	default:
+		err = gokey.EncodeToSignify(key, w)
+	}
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
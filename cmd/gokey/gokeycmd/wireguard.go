@@ -0,0 +1,84 @@
+package gokeycmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cloudflare/gokey"
+)
+
+// readWireGuardPeers parses the peer list file: one peer per line with the
+// peer name, tunnel address in CIDR notation and an optional public endpoint
+// separated by whitespace, empty lines and lines starting with # are ignored
+func readWireGuardPeers(path string) ([]gokey.WireGuardPeer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var peers []gokey.WireGuardPeer
+	scanner := bufio.NewScanner(f)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 || len(fields) > 3 {
+			return nil, fmt.Errorf("%v:%v: expected peer name, address and optional endpoint", path, lineNum)
+		}
+
+		peer := gokey.WireGuardPeer{Name: fields[0], Address: fields[1]}
+		if len(fields) == 3 {
+			peer.Endpoint = fields[2]
+		}
+		peers = append(peers, peer)
+	}
+
+	return peers, scanner.Err()
+}
+
+func genWireGuard(seed []byte, w io.Writer) {
+	peers, err := readWireGuardPeers(peersPath)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	configs, err := gokey.GetWireGuardConfigs(pass, realm, seed, peers, unsafe)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	if node != "" {
+		config, ok := configs[node]
+		if !ok {
+			logFatal("node %v is not in the peer list", node)
+		}
+
+		_, err = io.WriteString(w, config)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		return
+	}
+
+	err = os.MkdirAll(output, 0700)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	for _, peer := range peers {
+		err = ioutil.WriteFile(filepath.Join(output, peer.Name+".conf"), []byte(configs[peer.Name]), 0600)
+		if err != nil {
+			log.Fatalln(err)
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package gokey
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+// below code implements DNSKEY and DS records as described in RFC 4034, RFC
+// 5702, RFC 6605 and RFC 8080 and the key files written by BIND dnssec-keygen
+
+// DNSKEY flags
+const (
+	DNSSECZoneKey = 256
+	DNSSECSEPKey  = 257
+)
+
+// DS digest types
+const (
+	DSSHA256 = 2
+	DSSHA384 = 4
+)
+
+var dnssecAlgorithms = map[KeyType]uint8{
+	RSA2048: 8,
+	RSA4096: 8,
+	EC256:   13,
+	EC384:   14,
+	ED25519: 15,
+}
+
+var dnssecAlgorithmNames = map[uint8]string{
+	8:  "RSASHA256",
+	13: "ECDSAP256SHA256",
+	14: "ECDSAP384SHA384",
+	15: "ED25519",
+}
+
+// DNSSECKey is a DNSSEC signing key of a zone
+type DNSSECKey struct {
+	// Zone is the fully qualified zone name with a trailing dot
+	Zone string
+	// Flags is DNSSECSEPKey for key signing keys and DNSSECZoneKey for zone
+	// signing keys
+	Flags     uint16
+	Algorithm uint8
+	Key       crypto.PrivateKey
+}
+
+func canonicalZone(zone string) (string, error) {
+	zone = strings.ToLower(strings.TrimSuffix(zone, "."))
+	if zone == "" {
+		return ".", nil
+	}
+
+	for _, label := range strings.Split(zone, ".") {
+		if len(label) == 0 || len(label) > 63 {
+			return "", fmt.Errorf("invalid zone name %q", zone)
+		}
+	}
+
+	return zone + ".", nil
+}
+
+// wireName returns the canonical wire format of the zone name
+func wireName(zone string) []byte {
+	var name []byte
+	for _, label := range strings.Split(strings.TrimSuffix(zone, "."), ".") {
+		if label != "" {
+			name = append(name, byte(len(label)))
+			name = append(name, label...)
+		}
+	}
+
+	return append(name, 0)
+}
+
+// GetDNSSECKeys derives the key signing key and the zone signing key of the
+// zone. Supported key types are EC256, EC384, ED25519 and RSA2048/RSA4096
+// (RSASHA256). The keys are derived for the zone with separate "ksk" and "zsk"
+// components, so they are not the same as the keys output by GetKey for any
+// realm.
+func GetDNSSECKeys(password, zone string, seed []byte, kt KeyType, allowUnsafe bool) (*DNSSECKey, *DNSSECKey, error) {
+	alg, ok := dnssecAlgorithms[kt]
+	if !ok {
+		return nil, nil, errors.New("unsupported DNSSEC key type")
+	}
+
+	zone, err := canonicalZone(zone)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ksk, err := GetKey(password, protocolRealm(zone, "ksk"), seed, kt, allowUnsafe)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	zsk, err := GetKey(password, protocolRealm(zone, "zsk"), seed, kt, allowUnsafe)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &DNSSECKey{Zone: zone, Flags: DNSSECSEPKey, Algorithm: alg, Key: ksk},
+		&DNSSECKey{Zone: zone, Flags: DNSSECZoneKey, Algorithm: alg, Key: zsk}, nil
+}
+
+// padded returns the big-endian representation of n padded to size bytes
+func padded(n *big.Int, size int) []byte {
+	b := n.Bytes()
+	return append(make([]byte, size-len(b)), b...)
+}
+
+func (key *DNSSECKey) publicKey() ([]byte, error) {
+	switch k := key.Key.(type) {
+	case *ecdsa.PrivateKey:
+		size := (k.Curve.Params().BitSize + 7) / 8
+		return append(padded(k.X, size), padded(k.Y, size)...), nil
+	case *ed25519.PrivateKey:
+		return k.Public().(ed25519.PublicKey), nil
+	case *rsa.PrivateKey:
+		// RFC 3110: exponent length, exponent, modulus
+		e := big.NewInt(int64(k.E)).Bytes()
+		var pub []byte
+		if len(e) < 256 {
+			pub = []byte{byte(len(e))}
+		} else {
+			pub = []byte{0, byte(len(e) >> 8), byte(len(e))}
+		}
+		pub = append(pub, e...)
+		return append(pub, k.N.Bytes()...), nil
+	}
+
+	return nil, errors.New("unsupported DNSSEC key type")
+}
+
+// rdata returns the DNSKEY record data: flags, protocol, algorithm and the
+// public key
+func (key *DNSSECKey) rdata() ([]byte, error) {
+	pub, err := key.publicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	rdata := make([]byte, 4, 4+len(pub))
+	binary.BigEndian.PutUint16(rdata, key.Flags)
+	rdata[2] = 3
+	rdata[3] = key.Algorithm
+
+	return append(rdata, pub...), nil
+}
+
+// KeyTag returns the key tag of the key as defined in RFC 4034 Appendix B
+func (key *DNSSECKey) KeyTag() (uint16, error) {
+	rdata, err := key.rdata()
+	if err != nil {
+		return 0, err
+	}
+
+	var ac uint32
+	for i, b := range rdata {
+		if i&1 == 0 {
+			ac += uint32(b) << 8
+		} else {
+			ac += uint32(b)
+		}
+	}
+	ac += ac >> 16 & 0xffff
+
+	return uint16(ac), nil
+}
+
+// FileName returns the base name of BIND key files without the .key or
+// .private extension
+func (key *DNSSECKey) FileName() (string, error) {
+	tag, err := key.KeyTag()
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("K%s+%03d+%05d", key.Zone, key.Algorithm, tag), nil
+}
+
+// DNSKEY returns the DNSKEY resource record of the key
+func (key *DNSSECKey) DNSKEY() (string, error) {
+	rdata, err := key.rdata()
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s IN DNSKEY %d %d %d %s", key.Zone, key.Flags, rdata[2], key.Algorithm, base64.StdEncoding.EncodeToString(rdata[4:])), nil
+}
+
+// DS returns the DS resource record of the key with the requested digest type
+func (key *DNSSECKey) DS(digestType uint8) (string, error) {
+	var h []byte
+	rdata, err := key.rdata()
+	if err != nil {
+		return "", err
+	}
+
+	// digest = digest_algorithm(DNSKEY owner name | DNSKEY RDATA)
+	signed := append(wireName(key.Zone), rdata...)
+	switch digestType {
+	case DSSHA256:
+		digest := sha256.Sum256(signed)
+		h = digest[:]
+	case DSSHA384:
+		digest := sha512.Sum384(signed)
+		h = digest[:]
+	default:
+		return "", errors.New("unsupported DS digest type")
+	}
+
+	tag, err := key.KeyTag()
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s IN DS %d %d %d %s", key.Zone, tag, key.Algorithm, digestType, strings.ToUpper(hex.EncodeToString(h))), nil
+}
+
+// EncodePublicToBIND writes the BIND .key file of the key
+func EncodePublicToBIND(key *DNSSECKey, w io.Writer) error {
+	record, err := key.DNSKEY()
+	if err != nil {
+		return err
+	}
+
+	tag, err := key.KeyTag()
+	if err != nil {
+		return err
+	}
+
+	kind := "zone-signing"
+	if key.Flags == DNSSECSEPKey {
+		kind = "key-signing"
+	}
+
+	_, err = fmt.Fprintf(w, "; This is a %s key, keyid %d, for %s\n%s\n", kind, tag, key.Zone, record)
+	return err
+}
+
+// EncodeToBIND writes the BIND .private file of the key
+func EncodeToBIND(key *DNSSECKey, w io.Writer) error {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "Private-key-format: v1.3\n")
+	fmt.Fprintf(&b, "Algorithm: %d (%s)\n", key.Algorithm, dnssecAlgorithmNames[key.Algorithm])
+
+	field := func(name string, value []byte) {
+		fmt.Fprintf(&b, "%s: %s\n", name, base64.StdEncoding.EncodeToString(value))
+	}
+
+	switch k := key.Key.(type) {
+	case *ecdsa.PrivateKey:
+		field("PrivateKey", padded(k.D, (k.Curve.Params().BitSize+7)/8))
+	case *ed25519.PrivateKey:
+		field("PrivateKey", k.Seed())
+	case *rsa.PrivateKey:
+		k.Precompute()
+		field("Modulus", k.N.Bytes())
+		field("PublicExponent", big.NewInt(int64(k.E)).Bytes())
+		field("PrivateExponent", k.D.Bytes())
+		field("Prime1", k.Primes[0].Bytes())
+		field("Prime2", k.Primes[1].Bytes())
+		field("Exponent1", k.Precomputed.Dp.Bytes())
+		field("Exponent2", k.Precomputed.Dq.Bytes())
+		field("Coefficient", k.Precomputed.Qinv.Bytes())
+	default:
+		return errors.New("unsupported DNSSEC key type")
+	}
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
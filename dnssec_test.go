@@ -0,0 +1,125 @@
+package gokey
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/base64"
+	"math/big"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+func TestDNSSECVectors(t *testing.T) {
+	// RFC 8080 section 6.1
+	seed, _ := base64.StdEncoding.DecodeString("ODIyNjAzODQ2MjgwODAxMjI2NDUxOTAyMDQxNDIyNjI=")
+	edKey := ed25519.NewKeyFromSeed(seed)
+
+	// RFC 6605 section 6.1
+	d, _ := base64.StdEncoding.DecodeString("GU6SnQ/Ou+xC5RumuIUIuJZteXT2z0O/ok1s38Et6mQ=")
+	ecKey := &ecdsa.PrivateKey{D: new(big.Int).SetBytes(d)}
+	ecKey.Curve = elliptic.P256()
+	ecKey.X, ecKey.Y = ecKey.Curve.ScalarBaseMult(d)
+
+	for _, test := range []struct {
+		key    *DNSSECKey
+		dnskey string
+		ds     string
+	}{
+		{
+			&DNSSECKey{Zone: "example.com.", Flags: DNSSECSEPKey, Algorithm: 15, Key: &edKey},
+			"example.com. IN DNSKEY 257 3 15 l02Woi0iS8Aa25FQkUd9RMzZHJpBoRQwAQEX1SxZJA4=",
+			"example.com. IN DS 3613 15 2 3AA5AB37EFCE57F737FC1627013FEE07BDF241BD10F3B1964AB55C78E79A304B",
+		},
+		{
+			&DNSSECKey{Zone: "example.net.", Flags: DNSSECSEPKey, Algorithm: 13, Key: ecKey},
+			"example.net. IN DNSKEY 257 3 13 GojIhhXUN/u4v54ZQqGSnyhWJwaubCvTmeexv7bR6edbkrSqQpF64cYbcB7wNcP+e+MAnLr+Wi9xMWyQLc8NAA==",
+			"example.net. IN DS 55648 13 2 B4C8C1FE2E7477127B27115656AD6256F424625BF5C1E2770CE6D6E37DF61D17",
+		},
+	} {
+		dnskey, err := test.key.DNSKEY()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if dnskey != test.dnskey {
+			t.Fatalf("invalid DNSKEY record %v", dnskey)
+		}
+
+		ds, err := test.key.DS(DSSHA256)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if ds != test.ds {
+			t.Fatalf("invalid DS record %v", ds)
+		}
+	}
+}
+
+func TestDNSSECKeys(t *testing.T) {
+	for _, kt := range []KeyType{EC256, EC384, ED25519, RSA2048} {
+		ksk, zsk, err := GetDNSSECKeys("pass1", "Example.COM", nil, kt, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if ksk.Zone != "example.com." || ksk.Flags != DNSSECSEPKey || zsk.Flags != DNSSECZoneKey {
+			t.Fatal("invalid DNSSEC key parameters")
+		}
+
+		kskName, err := ksk.FileName()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		zskName, err := zsk.FileName()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if kskName == zskName || !strings.HasPrefix(kskName, "Kexample.com.+0") {
+			t.Fatalf("invalid key file names %v and %v", kskName, zskName)
+		}
+
+		var b strings.Builder
+		err = EncodeToBIND(ksk, &b)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.HasPrefix(b.String(), "Private-key-format: v1.3\n") {
+			t.Fatal("invalid BIND private key file")
+		}
+
+		_, err = ksk.DS(DSSHA384)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the signing keys of the zone are not derived for any realm typed by
+		// the user
+		for name, key := range map[string]*DNSSECKey{"example.com.-ksk": ksk, "example.com.-zsk": zsk} {
+			plain, err := GetKey("pass1", name, nil, kt, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if bytes.Equal(keyToBytes(plain, t), keyToBytes(key.Key, t)) {
+				t.Fatalf("DNSSEC key matches %v key for a crafted realm %v", kt, name)
+			}
+		}
+	}
+
+	_, _, err := GetDNSSECKeys("pass1", "example.com", nil, X25519, true)
+	if err == nil {
+		t.Fatal("allowed DNSSEC key with unsupported key type")
+	}
+
+	_, _, err = GetDNSSECKeys("pass1", "example..com", nil, EC256, true)
+	if err == nil {
+		t.Fatal("allowed invalid zone name")
+	}
+}
@@ -0,0 +1,115 @@
+package gokey
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// DecodePublicPem parses a PEM-encoded public key (SubjectPublicKeyInfo), as
+// produced by EncodePublicToPem
+func DecodePublicPem(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, errors.New("no PEM-encoded public key found")
+	}
+
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
+
+// agreementKeyType returns the type of the key, which should be derived to
+// agree on a shared secret with the peer public key
+func agreementKeyType(peer crypto.PublicKey) (KeyType, error) {
+	switch peer := peer.(type) {
+	case *ecdh.PublicKey:
+		if peer.Curve() == ecdh.X25519() {
+			return X25519, nil
+		}
+	case *ecdsa.PublicKey:
+		switch peer.Curve {
+		case elliptic.P256():
+			return EC256, nil
+		case elliptic.P384():
+			return EC384, nil
+		}
+	}
+
+	return 0, fmt.Errorf("key agreement is not supported for public key type %T", peer)
+}
+
+func ecdhPrivateKey(key crypto.PrivateKey) (*ecdh.PrivateKey, error) {
+	switch key := key.(type) {
+	case x25519PrivateKey:
+		return ecdh.X25519().NewPrivateKey(key)
+	case *ecdsa.PrivateKey:
+		return key.ECDH()
+	}
+
+	return nil, fmt.Errorf("key agreement is not supported for key type %T", key)
+}
+
+func ecdhPublicKey(key crypto.PublicKey) (*ecdh.PublicKey, error) {
+	switch key := key.(type) {
+	case *ecdh.PublicKey:
+		return key, nil
+	case *ecdsa.PublicKey:
+		return key.ECDH()
+	}
+
+	return nil, fmt.Errorf("key agreement is not supported for public key type %T", key)
+}
+
+// SharedKey performs ECDH key agreement between the private key and the peer
+// public key and expands the resulting shared secret with HKDF-SHA256 into
+// length bytes of symmetric key material bound to info
+func SharedKey(key crypto.PrivateKey, peer crypto.PublicKey, info []byte, length int) ([]byte, error) {
+	priv, err := ecdhPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	pub, err := ecdhPublicKey(peer)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := priv.ECDH(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	shared := make([]byte, length)
+	_, err = io.ReadFull(hkdf.New(sha256.New, secret, nil, info), shared)
+	if err != nil {
+		return nil, err
+	}
+
+	return shared, nil
+}
+
+// GetSharedKey derives the realm private key of the same type as the peer
+// public key (x25519, P-256 or P-384) and agrees on a shared symmetric key
+// with the peer. The peer gets the same key by calling GetSharedKey with its
+// own realm and our public key.
+func GetSharedKey(password, realm string, seed []byte, peer crypto.PublicKey, info []byte, length int, allowUnsafe bool) ([]byte, error) {
+	kt, err := agreementKeyType(peer)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := GetKey(password, realm, seed, kt, allowUnsafe)
+	if err != nil {
+		return nil, err
+	}
+
+	return SharedKey(key, peer, info, length)
+}
@@ -0,0 +1,102 @@
+package gokey
+
+import (
+	"bytes"
+	"crypto"
+	"testing"
+)
+
+func publicKey(t *testing.T, key crypto.PrivateKey) crypto.PublicKey {
+	var buf bytes.Buffer
+
+	err := EncodePublicToPem(key, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pub, err := DecodePublicPem(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pub
+}
+
+func testSharedKeyType(kt KeyType, t *testing.T) {
+	seed, err := GenerateEncryptedKeySeed("pass1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	alice, err := GetKey("pass1", "alice.example.com", seed, kt, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bob, err := GetKey("pass1", "bob.example.com", seed, kt, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	aliceShared, err := GetSharedKey("pass1", "alice.example.com", seed, publicKey(t, bob), []byte("info"), 32, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bobShared, err := GetSharedKey("pass1", "bob.example.com", seed, publicKey(t, alice), []byte("info"), 32, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(aliceShared, bobShared) {
+		t.Fatal("shared keys do not match")
+	}
+
+	otherInfo, err := SharedKey(alice, publicKey(t, bob), []byte("other info"), 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Equal(aliceShared, otherInfo) {
+		t.Fatal("shared keys match for different info")
+	}
+}
+
+func TestSharedKey(t *testing.T) {
+	for _, kt := range []KeyType{
+		EC256,
+		EC384,
+		X25519,
+	} {
+		t.Run(kt.String(), func(t *testing.T) {
+			testSharedKeyType(kt, t)
+		})
+	}
+}
+
+func TestSharedKeyMismatch(t *testing.T) {
+	alice, err := GetKey("pass1", "alice.example.com", nil, X25519, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bob, err := GetKey("pass1", "bob.example.com", nil, EC256, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = SharedKey(alice, publicKey(t, bob), nil, 32)
+	if err == nil {
+		t.Fatal("key agreement between different curves succeeded")
+	}
+
+	ed, err := GetKey("pass1", "bob.example.com", nil, ED25519, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = GetSharedKey("pass1", "alice.example.com", nil, publicKey(t, ed), nil, 32, true)
+	if err == nil {
+		t.Fatal("key agreement with ed25519 public key succeeded")
+	}
+}
@@ -0,0 +1,216 @@
+package gokey
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/bits"
+	"regexp/syntax"
+)
+
+// below code estimates the entropy of generated secrets in bits, which is the
+// base 2 logarithm of the number of equally likely secrets
+
+// DRNGEntropy is the size of the DRNG key in bits, which bounds the entropy of
+// any secret derived from a seed. Without a seed the entropy is bounded by the
+// master password strength.
+const DRNGEntropy = 256
+
+func log2Big(x *big.Int) float64 {
+	if x.Sign() <= 0 {
+		return 0
+	}
+
+	// keep 53 most significant bits, which fit into float64 mantissa
+	shift := x.BitLen() - 53
+	if shift < 0 {
+		shift = 0
+	}
+
+	mantissa, _ := new(big.Float).SetInt(new(big.Int).Rsh(x, uint(shift))).Float64()
+	return math.Log2(mantissa) + float64(shift)
+}
+
+// Entropy returns the entropy of the passwords generated for the spec. It
+// counts all passwords compliant with the spec, so it is exact for PasswordV0,
+// which chooses one of them with the same probability, and an estimate for
+// PasswordV1.
+func (spec *PasswordSpec) Entropy() float64 {
+	if spec.Validate() != nil {
+		return 0
+	}
+
+	return log2Big(spec.compliantCount())
+}
+
+// compliantCount returns the number of passwords made of the characters
+// allowed by the spec, which have the minimum number of characters of every
+// class. Passwords with less than the minimum number of characters of some
+// classes are subtracted with the inclusion-exclusion principle, so it takes
+// time for large minimums, but not for long passwords.
+func (spec *PasswordSpec) compliantCount() *big.Int {
+	classes, all := spec.passwordClasses()
+
+	count := new(big.Int)
+	for subset := 0; subset < 1<<len(classes); subset++ {
+		// ways[n] is the number of ways to place less than the minimum number
+		// of characters of every class in the subset into n positions of the
+		// password
+		ways := []*big.Int{big.NewInt(1)}
+		rest := len(all)
+		for i, class := range classes {
+			if subset&(1<<i) != 0 {
+				ways = placeFewer(ways, spec.Length, class)
+				rest -= len(class.chars)
+			}
+		}
+
+		// other positions get any character outside of the subset
+		term := new(big.Int)
+		for n, w := range ways {
+			fill := new(big.Int).Exp(big.NewInt(int64(rest)), big.NewInt(int64(spec.Length-n)), nil)
+			term.Add(term, fill.Mul(fill, w))
+		}
+
+		if bits.OnesCount(uint(subset))%2 == 0 {
+			count.Add(count, term)
+		} else {
+			count.Sub(count, term)
+		}
+	}
+
+	return count
+}
+
+// placeFewer adds less than the minimum number of characters of the class to
+// the ways to fill n positions of the password
+func placeFewer(ways []*big.Int, length int, class passwordClass) []*big.Int {
+	size := big.NewInt(int64(len(class.chars)))
+	next := make([]*big.Int, len(ways)+class.count-1)
+	for n := range next {
+		next[n] = new(big.Int)
+	}
+
+	for n, w := range ways {
+		// k characters of the class can be placed into the remaining positions
+		// in "length - n choose k" ways
+		choices := big.NewInt(1)
+		for k := 0; k < class.count && n+k <= length; k++ {
+			next[n+k].Add(next[n+k], new(big.Int).Mul(w, choices))
+			choices.Mul(choices, big.NewInt(int64(length-n-k)))
+			choices.Mul(choices, size)
+			choices.Quo(choices, big.NewInt(int64(k+1)))
+		}
+	}
+
+	return next
+}
+
+// Entropy returns the entropy of the passphrases generated for the spec
+func (spec *PassphraseSpec) Entropy() float64 {
+	if spec.Words <= 0 {
+		return 0
+	}
+
+	entropy := float64(spec.Words) * math.Log2(float64(len(spec.wordlist())))
+	if spec.Digit {
+		entropy += math.Log2(10) + math.Log2(float64(spec.Words))
+	}
+	if spec.Symbol {
+		entropy += math.Log2(float64(len(passphraseSymbols))) + math.Log2(float64(spec.Words))
+	}
+
+	return entropy
+}
+
+// PINEntropy returns the entropy of the PINs generated by GeneratePIN. PINs up
+// to 6 digits are counted exactly, weak patterns are a negligible part of longer
+// PINs.
+func PINEntropy(length int) float64 {
+	if length < MinPINLength || length > MaxPINLength {
+		return 0
+	}
+
+	if length > 6 {
+		return float64(length) * math.Log2(10)
+	}
+
+	max := int(math.Pow10(length))
+	strong := 0
+	for i := 0; i < max; i++ {
+		if !WeakPIN(fmt.Sprintf("%0*d", length, i)) {
+			strong++
+		}
+	}
+
+	return math.Log2(float64(strong))
+}
+
+func regexpEntropy(re *syntax.Regexp) (float64, error) {
+	switch re.Op {
+	case syntax.OpEmptyMatch, syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText, syntax.OpLiteral:
+		return 0, nil
+	case syntax.OpCharClass, syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		ranges := re.Rune
+		if re.Op != syntax.OpCharClass {
+			ranges = []rune{regexpMinRune, regexpMaxRune}
+		}
+
+		_, size := regexpClip(ranges)
+		return math.Log2(float64(size)), nil
+	case syntax.OpCapture:
+		return regexpEntropy(re.Sub[0])
+	case syntax.OpConcat:
+		var entropy float64
+		for _, sub := range re.Sub {
+			e, err := regexpEntropy(sub)
+			if err != nil {
+				return 0, err
+			}
+			entropy += e
+		}
+		return entropy, nil
+	case syntax.OpAlternate:
+		// every alternative is chosen with the same probability
+		var entropy float64
+		for _, sub := range re.Sub {
+			e, err := regexpEntropy(sub)
+			if err != nil {
+				return 0, err
+			}
+			entropy += e
+		}
+		return math.Log2(float64(len(re.Sub))) + entropy/float64(len(re.Sub)), nil
+	case syntax.OpQuest, syntax.OpRepeat:
+		min, max := 0, 1
+		if re.Op == syntax.OpRepeat {
+			min, max = re.Min, re.Max
+		}
+		if max < 0 {
+			return 0, errRegexpUnbounded
+		}
+
+		e, err := regexpEntropy(re.Sub[0])
+		if err != nil {
+			return 0, err
+		}
+		// every repetition count is chosen with the same probability
+		return math.Log2(float64(max-min+1)) + e*float64(min+max)/2, nil
+	case syntax.OpStar, syntax.OpPlus:
+		return 0, errRegexpUnbounded
+	}
+
+	return 0, fmt.Errorf("unsupported construct in the password pattern: %v", re)
+}
+
+// RegexpEntropy returns the entropy of the choices GenerateRegexpPassword makes
+// for the pattern. It is an upper bound, as different choices may produce the
+// same password, for example for "(a|a)".
+func RegexpEntropy(pattern string) (float64, error) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return 0, fmt.Errorf("invalid password pattern: %v", err)
+	}
+
+	return regexpEntropy(re)
+}
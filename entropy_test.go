@@ -0,0 +1,88 @@
+package gokey
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPasswordSpecEntropy(t *testing.T) {
+	for _, spec := range []*PasswordSpec{
+		{Length: 5, Upper: 1, Lower: 2, Digits: 0, Special: 1, Alphabet: "aBc1!"},
+		{Length: 7, Upper: 2, Lower: 1, Digits: 1, Special: 2, Alphabet: "aBC1!?一"},
+		{Length: 6, Upper: 3, Lower: 3, Digits: 0, Special: 0, Alphabet: "abCD"},
+	} {
+		alphabet := []rune(spec.Alphabet)
+
+		// count compliant passwords by brute force
+		compliant := 0
+		password := make([]rune, spec.Length)
+		var count func(pos int)
+		count = func(pos int) {
+			if pos == len(password) {
+				if spec.Compliant(string(password)) {
+					compliant++
+				}
+				return
+			}
+
+			for _, c := range alphabet {
+				password[pos] = c
+				count(pos + 1)
+			}
+		}
+		count(0)
+
+		if entropy := spec.Entropy(); math.Abs(entropy-math.Log2(float64(compliant))) > 1e-9 {
+			t.Fatalf("entropy %v, expected %v for spec %+v", entropy, math.Log2(float64(compliant)), spec)
+		}
+	}
+
+	// every password of a single class alphabet is compliant
+	spec := &PasswordSpec{Length: 20, Upper: 0, Lower: 1, Digits: 0, Special: 0, Alphabet: "abcdefgh"}
+	if entropy := spec.Entropy(); math.Abs(entropy-60) > 1e-9 {
+		t.Fatalf("entropy %v, expected 60", entropy)
+	}
+
+	// almost every long password is compliant, the count does not take time
+	// for long passwords
+	spec = &PasswordSpec{Length: 10000, Upper: 3, Lower: 3, Digits: 1, Special: 1}
+	if entropy := spec.Entropy(); math.Abs(entropy-10000*math.Log2(float64(len(chars)))) > 1e-6 {
+		t.Fatalf("entropy %v, expected %v", entropy, 10000*math.Log2(float64(len(chars))))
+	}
+}
+
+func TestPassphraseSpecEntropy(t *testing.T) {
+	spec := &PassphraseSpec{Words: 6}
+	if entropy := spec.Entropy(); math.Abs(entropy-6*math.Log2(7776)) > 1e-9 {
+		t.Fatalf("entropy %v, expected %v", entropy, 6*math.Log2(7776))
+	}
+}
+
+func TestPINEntropy(t *testing.T) {
+	if entropy := PINEntropy(4); math.Abs(entropy-math.Log2(8952)) > 1e-9 {
+		t.Fatalf("entropy %v, expected %v", entropy, math.Log2(8952))
+	}
+}
+
+func TestRegexpEntropy(t *testing.T) {
+	for pattern, expected := range map[string]float64{
+		`[a-z]{8}`:        8 * math.Log2(26),
+		`^(ab|cd)$`:       1,
+		`x[0-9]?`:         1 + math.Log2(10)/2,
+		`(a|[0-9]{2,4})!`: 1 + (math.Log2(3)+3*math.Log2(10))/2,
+	} {
+		entropy, err := RegexpEntropy(pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if math.Abs(entropy-expected) > 1e-9 {
+			t.Fatalf("entropy of %v is %v, expected %v", pattern, entropy, expected)
+		}
+	}
+
+	_, err := RegexpEntropy(`[a-z]+`)
+	if err == nil {
+		t.Fatal("estimated entropy of unbounded pattern")
+	}
+}
@@ -0,0 +1,71 @@
+package gokey
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// below code implements automatic rotation of passwords and keys: time is
+// divided into periods of the same length (epochs) counted from the start
+// time and the number of the epoch is mixed into the realm, so a new value is
+// generated for every period
+
+// EpochSpec describes the rotation periods
+type EpochSpec struct {
+	// Start is the beginning of the epoch 0
+	Start time.Time
+	// Period is the length of every epoch
+	Period time.Duration
+}
+
+// Validate checks, if the epoch spec can be used
+func (spec *EpochSpec) Validate() error {
+	if spec.Period <= 0 {
+		return errors.New("invalid epoch period")
+	}
+
+	return nil
+}
+
+// unixNano is time.UnixNano, which does not overflow for the times outside of
+// years 1678 to 2262
+func unixNano(t time.Time) *big.Int {
+	nanos := new(big.Int).Mul(big.NewInt(t.Unix()), big.NewInt(int64(time.Second)))
+	return nanos.Add(nanos, big.NewInt(int64(t.Nanosecond())))
+}
+
+// Epoch returns the number of the epoch containing the time t. Epochs before
+// the start time have negative numbers.
+func (spec *EpochSpec) Epoch(t time.Time) int64 {
+	// time.Time.Sub saturates about 292 years away from the start, so the
+	// elapsed time is computed from Unix times. Euclidean division rounds down
+	// for the positive period, so the times before the start get negative
+	// epochs.
+	elapsed := new(big.Int).Sub(unixNano(t), unixNano(spec.Start))
+	return elapsed.Div(elapsed, big.NewInt(int64(spec.Period))).Int64()
+}
+
+// begin returns the beginning of the epoch
+func (spec *EpochSpec) begin(epoch int64) time.Time {
+	nanos := new(big.Int).Mul(big.NewInt(epoch), big.NewInt(int64(spec.Period)))
+	nanos.Add(nanos, unixNano(spec.Start))
+	sec, nsec := nanos.DivMod(nanos, big.NewInt(int64(time.Second)), new(big.Int))
+	return time.Unix(sec.Int64(), nsec.Int64()).In(spec.Start.Location())
+}
+
+// Bounds returns the beginning and the end of the epoch. The end is the
+// beginning of the next epoch.
+func (spec *EpochSpec) Bounds(epoch int64) (time.Time, time.Time) {
+	return spec.begin(epoch), spec.begin(epoch + 1)
+}
+
+// EpochRealm returns the realm for the epoch. Like the rotation counter in
+// CounterRealm, the epoch is appended to the realm as a separate component
+// starting with a NUL byte, so it never matches a realm typed by the user or
+// the realm with a counter. When both are used, the epoch is applied first:
+// CounterRealm(EpochRealm(realm, epoch), counter).
+func EpochRealm(realm string, epoch int64) string {
+	return fmt.Sprintf("%s\x00epoch=%d", realm, epoch)
+}
@@ -0,0 +1,75 @@
+package gokey
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEpoch(t *testing.T) {
+	spec := &EpochSpec{Start: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Period: 90 * 24 * time.Hour}
+	if err := spec.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		time  time.Time
+		epoch int64
+	}{
+		{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), 0},
+		{time.Date(2024, 3, 30, 23, 59, 59, 0, time.UTC), 0},
+		{time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC), 1},
+		{time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC), 11},
+		{time.Date(2023, 12, 31, 23, 59, 59, 0, time.UTC), -1},
+		{time.Date(2023, 10, 3, 0, 0, 0, 0, time.UTC), -1},
+		{time.Date(2023, 10, 2, 23, 59, 59, 0, time.UTC), -2},
+		// time.Duration only covers about 292 years
+		{time.Date(2300, 1, 1, 0, 0, 0, 0, time.UTC), 1120},
+		{time.Date(2400, 1, 1, 0, 0, 0, 0, time.UTC), 1525},
+		{time.Date(1700, 1, 1, 0, 0, 0, 0, time.UTC), -1315},
+	} {
+		epoch := spec.Epoch(test.time)
+		if epoch != test.epoch {
+			t.Fatalf("epoch of %v is %v, expected %v", test.time, epoch, test.epoch)
+		}
+
+		begin, end := spec.Bounds(epoch)
+		if test.time.Before(begin) || !test.time.Before(end) {
+			t.Fatalf("%v is not in the bounds of epoch %v: %v - %v", test.time, epoch, begin, end)
+		}
+	}
+
+	spec.Period = 0
+	if spec.Validate() == nil {
+		t.Fatal("epoch spec with zero period is valid")
+	}
+}
+
+func TestEpochRealm(t *testing.T) {
+	// the realm for the epoch is a part of the derivation, so it must not change
+	if EpochRealm("example.com", 11) != "example.com\x00epoch=11" {
+		t.Fatal("unexpected realm for epoch 11")
+	}
+
+	if EpochRealm("example.com", 1) == CounterRealm("example.com", 1) {
+		t.Fatal("epoch and counter realms match")
+	}
+
+	// neither literal realms nor other epochs and counters produce the same
+	// realm as the epoch combined with the counter
+	realms := map[string]bool{}
+	for _, realm := range []string{
+		"example.com@epoch1#2",
+		"example.com@epoch12",
+		EpochRealm("example.com", 1),
+		EpochRealm("example.com", 12),
+		CounterRealm("example.com", 12),
+		CounterRealm(EpochRealm("example.com", 1), 2),
+		CounterRealm(EpochRealm("example.com", 12), 1),
+		CounterRealm(EpochRealm("example.com1", 1), 2),
+	} {
+		if realms[realm] {
+			t.Fatalf("realm %q collides with another realm", realm)
+		}
+		realms[realm] = true
+	}
+}
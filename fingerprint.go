@@ -0,0 +1,37 @@
+package gokey
+
+import (
+	"bytes"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// below code computes a short fingerprint of the master password, so a mistyped
+// master password is noticed before the wrong passwords and keys are used. The
+// fingerprint only has about 26 bits, so it does not reveal the master password,
+// and it is computed with a slow KDF, so it can not be used to check guesses of
+// the master password quickly.
+
+const fingerprintSalt = "gokey master password fingerprint"
+
+// Fingerprint returns two words from the EFF large wordlist derived from the
+// master password
+func Fingerprint(password string) (string, error) {
+	key, err := scrypt.Key([]byte(password), []byte(fingerprintSalt), 1<<16, 8, 1, 32)
+	if err != nil {
+		return "", err
+	}
+
+	rng := bytes.NewReader(key)
+	words := make([]string, 2)
+	for i := range words {
+		pos, err := randIndex(rng, len(effLargeWords))
+		if err != nil {
+			return "", err
+		}
+		words[i] = effLargeWords[pos]
+	}
+
+	return strings.Join(words, " "), nil
+}
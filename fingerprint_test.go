@@ -0,0 +1,35 @@
+package gokey
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	fp1, err := Fingerprint("super-secret-master-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fp2, err := Fingerprint("super-secret-master-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fp1 != fp2 {
+		t.Fatal("fingerprints of the same password do not match")
+	}
+
+	if len(strings.Fields(fp1)) != 2 {
+		t.Fatalf("invalid fingerprint %v", fp1)
+	}
+
+	fp2, err = Fingerprint("super-secret-master-passwort")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fp1 == fp2 {
+		t.Fatal("fingerprints of different passwords match")
+	}
+}
@@ -1,8 +1,13 @@
 module github.com/cloudflare/gokey
 
-go 1.13
+go 1.20
 
 require (
+	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.3.0
 	golang.org/x/crypto v0.31.0
+	golang.org/x/net v0.33.0
 	golang.org/x/term v0.27.0
+	golang.org/x/text v0.21.0
 )
+
+require golang.org/x/sys v0.28.0 // indirect
@@ -2,6 +2,7 @@ package gokey
 
 import (
 	"crypto"
+	"crypto/ecdh"
 	"crypto/ecdsa"
 	"crypto/rsa"
 	"crypto/x509"
@@ -62,6 +63,32 @@ func GetRaw(password, realm string, seed []byte, allowUnsafe bool) (io.Reader, e
 	return rng, nil
 }
 
+// CounterRealm returns the realm for the rotation counter, so a new password
+// or key can be generated for the same purpose. The result is passed to
+// GetPass, GetKey, GetRaw or any other function taking a realm. Counter 0
+// returns the realm itself, so passwords and keys generated without a counter
+// do not change. Other counters are appended to the realm as a separate
+// component starting with a NUL byte, which can not be a part of a realm, so
+// the realm with a counter never matches a realm typed by the user.
+func CounterRealm(realm string, counter int) string {
+	if counter == 0 {
+		return realm
+	}
+
+	return fmt.Sprintf("%s\x00counter=%d", realm, counter)
+}
+
+// protocolRealm returns the realm for the keys of a protocol built on top of
+// GetKey or GetRaw. Like the rotation counter, every component is appended to
+// the realm after a NUL byte, so no realm typed by the user gives the same keys.
+func protocolRealm(realm string, components ...string) string {
+	for _, component := range components {
+		realm += "\x00" + component
+	}
+
+	return realm
+}
+
 // below code implements asn1 encoding of x25519 and ed25519 keys according
 // to https://tools.ietf.org/id/draft-ietf-curdle-pkix-10.txt
 // the output should be compatible to OpenSSL pkey functions
@@ -90,16 +117,36 @@ type asn25519 struct {
 // Golang does not have a declaration for x25519 keys
 type x25519PrivateKey []byte
 
+// Public returns the x25519 public key as a crypto/ecdh key, so it can be
+// marshalled with the standard x509 package
+func (key x25519PrivateKey) Public() crypto.PublicKey {
+	priv, err := ecdh.X25519().NewPrivateKey(key)
+	if err != nil {
+		return nil
+	}
+
+	return priv.PublicKey()
+}
+
+func x25519PublicKey(key x25519PrivateKey) ([]byte, error) {
+	priv, err := ecdh.X25519().NewPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return priv.PublicKey().Bytes(), nil
+}
+
 func marshal25519PrivateKey(key crypto.PrivateKey) ([]byte, error) {
 	var a25519 asn25519
 	var keyBytes []byte
 
 	switch key.(type) {
 	case x25519PrivateKey:
-		a25519.AlgId = pkix.AlgorithmIdentifier{asn1.ObjectIdentifier{1, 3, 101, x25519OidSuffix}, asn1.RawValue{}}
+		a25519.AlgId = pkix.AlgorithmIdentifier{Algorithm: asn1.ObjectIdentifier{1, 3, 101, x25519OidSuffix}}
 		keyBytes = key.(x25519PrivateKey)
 	case *ed25519.PrivateKey:
-		a25519.AlgId = pkix.AlgorithmIdentifier{asn1.ObjectIdentifier{1, 3, 101, ed25519OidSuffix}, asn1.RawValue{}}
+		a25519.AlgId = pkix.AlgorithmIdentifier{Algorithm: asn1.ObjectIdentifier{1, 3, 101, ed25519OidSuffix}}
 		keyBytes = key.(*ed25519.PrivateKey).Seed()
 	}
 
@@ -138,3 +185,17 @@ func EncodeToPem(key crypto.PrivateKey, w io.Writer) error {
 
 	return fmt.Errorf("unable to encode key type %T", key)
 }
+
+func EncodePublicToPem(key crypto.PrivateKey, w io.Writer) error {
+	priv, ok := key.(interface{ Public() crypto.PublicKey })
+	if !ok {
+		return fmt.Errorf("unable to encode public key for key type %T", key)
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(priv.Public())
+	if err != nil {
+		return err
+	}
+
+	return pem.Encode(w, &pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
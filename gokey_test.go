@@ -6,6 +6,7 @@ import (
 	"encoding/asn1"
 	"encoding/hex"
 	"encoding/pem"
+	"io"
 	"reflect"
 	"strings"
 	"testing"
@@ -13,7 +14,7 @@ import (
 	"golang.org/x/crypto/ed25519"
 )
 
-var passSpec = &PasswordSpec{16, 3, 3, 2, 1, ""}
+var passSpec = &PasswordSpec{Length: 16, Upper: 3, Lower: 3, Digits: 2, Special: 1}
 
 func TestGetPass(t *testing.T) {
 	pass1Seed1, err := GenerateEncryptedKeySeed("pass1")
@@ -160,6 +161,55 @@ func testGetKeyType(kt KeyType, t *testing.T) {
 	}
 }
 
+func TestCounterRealm(t *testing.T) {
+	if CounterRealm("example.com", 0) != "example.com" {
+		t.Fatal("counter 0 changes the realm")
+	}
+
+	// the realm for the counter is a part of the derivation, so it must not change
+	if CounterRealm("example.com", 1) != "example.com\x00counter=1" {
+		t.Fatal("unexpected realm for counter 1")
+	}
+
+	// the counter must not collide with realms, which look like a counter
+	for _, realm := range []string{"example.com#1", "example.com1", "example.comcounter=1"} {
+		if CounterRealm("example.com", 1) == realm || CounterRealm(realm, 0) == CounterRealm("example.com", 1) {
+			t.Fatalf("realm %q collides with counter 1", realm)
+		}
+	}
+
+	pass1, err := GetPass("pass1", CounterRealm("example.com", 1), nil, passSpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pass2, err := GetPass("pass1", CounterRealm("example.com", 2), nil, passSpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pass1 == pass2 {
+		t.Fatal("passwords match for different counters")
+	}
+
+	raw1, err := GetRaw("pass1", CounterRealm("example.com", 1), nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw2, err := GetRaw("pass1", CounterRealm("example.com", 2), nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf1, buf2 := make([]byte, 32), make([]byte, 32)
+	io.ReadFull(raw1, buf1)
+	io.ReadFull(raw2, buf2)
+	if bytes.Equal(buf1, buf2) {
+		t.Fatal("raw streams match for different counters")
+	}
+}
+
 func TestGetKey(t *testing.T) {
 	for _, kt := range []KeyType{
 		EC256,
@@ -3,8 +3,11 @@ package gokey
 import (
 	"crypto"
 	"crypto/elliptic"
+	"encoding/binary"
 	"errors"
+	"fmt"
 	"io"
+	"math"
 	"strings"
 	"unicode"
 
@@ -31,6 +34,27 @@ type KeyGen struct {
 	rng io.Reader
 }
 
+// Password generation algorithms. Passwords for existing realms only stay the
+// same with the algorithm they were generated with, so PasswordV0 is the
+// default.
+const (
+	// PasswordV0 generates random passwords until one complies with the spec,
+	// which gets slow for strict specs
+	PasswordV0 = iota
+	// PasswordV1 places the required characters of every class, fills the
+	// rest with allowed characters and shuffles the result
+	PasswordV1
+)
+
+// legacyMaxChars limits the number of random characters the legacy algorithm
+// is expected to generate, before it gets a compliant password
+const legacyMaxChars = 1 << 24
+
+// ErrPasswordSpecTooStrict is returned by PasswordV0 algorithm, when it would
+// take too long to generate a password compliant with the spec. PasswordV1
+// generates such passwords without retries.
+var ErrPasswordSpecTooStrict = errors.New("password specification is too strict for the legacy algorithm, use PasswordV1")
+
 type PasswordSpec struct {
 	Length         int
 	Upper          int
@@ -38,18 +62,69 @@ type PasswordSpec struct {
 	Digits         int
 	Special        int
 	AllowedSpecial string
+	// Version is the password generation algorithm
+	Version int
+	// Alphabet is the characters the password is made of, any Unicode
+	// characters are supported. Default is printable ASCII characters.
+	Alphabet string
+	// ExcludeAmbiguous removes the characters, which are easy to confuse
+	// (0/O, 1/l/I), from the alphabet
+	ExcludeAmbiguous bool
 }
 
-func (spec *PasswordSpec) Valid() bool {
+// Validate returns the reason, why no password can satisfy the spec. Zero
+// count of a character class means the class is not allowed in the password.
+func (spec *PasswordSpec) Validate() error {
+	if spec.Version != PasswordV0 && spec.Version != PasswordV1 {
+		return fmt.Errorf("unknown password generation algorithm version %v", spec.Version)
+	}
+
+	if spec.Length <= 0 {
+		return errors.New("password length should be positive")
+	}
+
+	if spec.Upper < 0 || spec.Lower < 0 || spec.Digits < 0 || spec.Special < 0 {
+		return errors.New("minimum character counts should not be negative")
+	}
+
+	if spec.Length < spec.Upper+spec.Lower+spec.Digits+spec.Special {
+		return fmt.Errorf("password length %v is less than the sum of minimum character counts %v", spec.Length, spec.Upper+spec.Lower+spec.Digits+spec.Special)
+	}
+
 	if spec.AllowedSpecial != "" {
 		for _, c := range spec.AllowedSpecial {
 			if !unicode.IsSymbol(c) && !unicode.IsPunct(c) {
-				return false
+				return fmt.Errorf("allowed special character %q is not a symbol or punctuation", c)
 			}
 		}
+
+	}
+
+	for _, c := range spec.Alphabet {
+		if !unicode.IsGraphic(c) || unicode.IsSpace(c) {
+			return fmt.Errorf("alphabet character %q is not printable", c)
+		}
 	}
 
-	return spec.Length >= spec.Upper+spec.Lower+spec.Digits+spec.Special
+	classes, all := spec.passwordClasses()
+	if len(all) == 0 {
+		return errors.New("all character classes are disabled")
+	}
+
+	for _, class := range classes {
+		if len(class.chars) == 0 {
+			if class.name == "special" && spec.AllowedSpecial != "" {
+				return errors.New("none of the allowed special characters can be generated")
+			}
+			return fmt.Errorf("no %v characters in the alphabet", class.name)
+		}
+	}
+
+	return nil
+}
+
+func (spec *PasswordSpec) Valid() bool {
+	return spec.Validate() == nil
 }
 
 func allowed(num, fromSpec int) bool {
@@ -123,6 +198,34 @@ func randRange(rng io.Reader, max byte) (byte, error) {
 	}
 }
 
+// randIndex is randRange for ranges, which do not fit into a byte
+func randIndex(rng io.Reader, max int) (int, error) {
+	if max <= 0 || uint64(max) > math.MaxUint32 {
+		return 0, errors.New("invalid random range")
+	}
+
+	var base [4]byte
+
+	for {
+		_, err := io.ReadFull(rng, base[:])
+		if err != nil {
+			return 0, err
+		}
+
+		value := binary.BigEndian.Uint32(base[:])
+		if math.MaxUint32 == value {
+			continue
+		}
+
+		rem := math.MaxUint32 % uint32(max)
+		buck := math.MaxUint32 / uint32(max)
+
+		if value < math.MaxUint32-rem {
+			return int(value / buck), nil
+		}
+	}
+}
+
 func (keygen *KeyGen) genRandStr(length int) (string, error) {
 	bytes := make([]byte, length)
 
@@ -138,13 +241,189 @@ func (keygen *KeyGen) genRandStr(length int) (string, error) {
 	return string(bytes), nil
 }
 
+// ambiguous are the characters, which are easy to confuse with each other
+const ambiguous = "0O1lI|"
+
+// alphabet returns the distinct characters passwords are made of
+func (spec *PasswordSpec) alphabet() []rune {
+	source := spec.Alphabet
+	if source == "" {
+		source = chars
+	}
+
+	var alphabet []rune
+	seen := make(map[rune]bool)
+	for _, c := range source {
+		if seen[c] || (spec.ExcludeAmbiguous && strings.ContainsRune(ambiguous, c)) {
+			continue
+		}
+		seen[c] = true
+		alphabet = append(alphabet, c)
+	}
+
+	return alphabet
+}
+
+// legacy reports, if the spec can only be satisfied by passwords made of the
+// original alphabet with all character classes, as the only spec before the
+// alphabet and the character classes became configurable
+func (spec *PasswordSpec) legacy() bool {
+	return spec.Alphabet == "" && !spec.ExcludeAmbiguous && spec.AllowedSpecial == "" &&
+		spec.Upper > 0 && spec.Lower > 0 && spec.Digits > 0 && spec.Special > 0
+}
+
+// allowedChars returns the characters of the alphabet allowed by the spec in
+// the order of the alphabet
+func (spec *PasswordSpec) allowedChars() []rune {
+	_, all := spec.passwordClasses()
+	allowed := make(map[rune]bool, len(all))
+	for _, c := range all {
+		allowed[c] = true
+	}
+
+	var alphabet []rune
+	for _, c := range spec.alphabet() {
+		if allowed[c] {
+			alphabet = append(alphabet, c)
+		}
+	}
+
+	return alphabet
+}
+
+type passwordClass struct {
+	name  string
+	chars []rune
+	count int
+}
+
+// passwordClasses returns the character classes required by the spec and all
+// characters allowed by the spec. Characters, which are not in any class (for
+// example letters without case), are always allowed.
+func (spec *PasswordSpec) passwordClasses() ([]passwordClass, []rune) {
+	var upper, lower, digits, special, other []rune
+	for _, c := range spec.alphabet() {
+		switch {
+		case unicode.IsUpper(c):
+			upper = append(upper, c)
+		case unicode.IsLower(c):
+			lower = append(lower, c)
+		case unicode.IsDigit(c):
+			digits = append(digits, c)
+		case unicode.IsSymbol(c) || unicode.IsPunct(c):
+			if spec.AllowedSpecial == "" || strings.ContainsRune(spec.AllowedSpecial, c) {
+				special = append(special, c)
+			}
+		default:
+			other = append(other, c)
+		}
+	}
+
+	var classes []passwordClass
+	var all []rune
+	for _, class := range []passwordClass{
+		{"upper case", upper, spec.Upper},
+		{"lower case", lower, spec.Lower},
+		{"digit", digits, spec.Digits},
+		{"special", special, spec.Special},
+	} {
+		if class.count > 0 {
+			classes = append(classes, class)
+			all = append(all, class.chars...)
+		}
+	}
+
+	return classes, append(all, other...)
+}
+
+func (keygen *KeyGen) randRune(class []rune) (rune, error) {
+	pos, err := randIndex(keygen.rng, len(class))
+	if err != nil {
+		return 0, err
+	}
+
+	return class[pos], nil
+}
+
+// genRandRunes is genRandStr for any alphabet
+func (keygen *KeyGen) genRandRunes(alphabet []rune, length int) (string, error) {
+	password := make([]rune, length)
+	for i := range password {
+		c, err := keygen.randRune(alphabet)
+		if err != nil {
+			return "", err
+		}
+		password[i] = c
+	}
+
+	return string(password), nil
+}
+
+func (keygen *KeyGen) genConstructive(spec *PasswordSpec) (string, error) {
+	classes, all := spec.passwordClasses()
+
+	password := make([]rune, 0, spec.Length)
+	for _, class := range classes {
+		for i := 0; i < class.count; i++ {
+			c, err := keygen.randRune(class.chars)
+			if err != nil {
+				return "", err
+			}
+			password = append(password, c)
+		}
+	}
+
+	for len(password) < spec.Length {
+		c, err := keygen.randRune(all)
+		if err != nil {
+			return "", err
+		}
+		password = append(password, c)
+	}
+
+	// Fisher-Yates shuffle
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := randIndex(keygen.rng, i+1)
+		if err != nil {
+			return "", err
+		}
+		password[i], password[j] = password[j], password[i]
+	}
+
+	return string(password), nil
+}
+
 func (keygen *KeyGen) GeneratePassword(spec *PasswordSpec) (string, error) {
-	if !spec.Valid() {
-		return "", errors.New("invalid password specification")
+	err := spec.Validate()
+	if err != nil {
+		return "", fmt.Errorf("invalid password specification: %v", err)
+	}
+
+	if spec.Version == PasswordV1 {
+		return keygen.genConstructive(spec)
+	}
+
+	// the original alphabet is sampled byte-wise, so passwords for existing
+	// realms do not change. Other specs only sample the allowed characters,
+	// otherwise the chance to get a compliant password drops exponentially with
+	// the length, when a character class is not allowed.
+	legacy := spec.legacy()
+	alphabet := spec.allowedChars()
+
+	// the number of attempts is on average the number of passwords made of the
+	// alphabet per compliant password
+	attempts := float64(spec.Length)*math.Log2(float64(len(alphabet))) - log2Big(spec.compliantCount())
+	if attempts+math.Log2(float64(spec.Length)) > math.Log2(legacyMaxChars) {
+		return "", ErrPasswordSpecTooStrict
 	}
 
 	for {
-		password, err := keygen.genRandStr(spec.Length)
+		var password string
+		if !legacy {
+			password, err = keygen.genRandRunes(alphabet, spec.Length)
+		} else {
+			password, err = keygen.genRandStr(spec.Length)
+		}
 		if err != nil {
 			return "", err
 		}
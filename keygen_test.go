@@ -2,12 +2,13 @@ package gokey
 
 import (
 	"crypto/rand"
+	"strings"
 	"testing"
 	"unicode"
 )
 
 func TestGenPass(t *testing.T) {
-	spec := &PasswordSpec{16, 2, 2, 1, 1, ""}
+	spec := &PasswordSpec{Length: 16, Upper: 2, Lower: 2, Digits: 1, Special: 1}
 	keygen := &KeyGen{rand.Reader}
 
 	_, err := keygen.GeneratePassword(spec)
@@ -23,3 +24,176 @@ func TestAllowedChars(t *testing.T) {
 		}
 	}
 }
+
+func TestPasswordSpecValidate(t *testing.T) {
+	for _, spec := range []*PasswordSpec{
+		{Length: 0, Upper: 0, Lower: 1},
+		{Length: 10, Upper: -1, Lower: 1},
+		{Length: 10},
+		{Length: 4, Upper: 2, Lower: 2, Digits: 1},
+		{Length: 10, Lower: 1, Special: 1, AllowedSpecial: "!a"},
+		{Length: 10, Lower: 1, Special: 1, AllowedSpecial: "€"},
+	} {
+		if spec.Validate() == nil || spec.Valid() {
+			t.Fatalf("unsatisfiable spec %+v is valid", spec)
+		}
+
+		_, err := (&KeyGen{rand.Reader}).GeneratePassword(spec)
+		if err == nil {
+			t.Fatalf("generated password for unsatisfiable spec %+v", spec)
+		}
+	}
+
+	spec := &PasswordSpec{Length: 8, Lower: 1, Digits: 2, Special: 1, AllowedSpecial: "!@#"}
+	if err := spec.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	password, err := (&KeyGen{rand.Reader}).GeneratePassword(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.ContainsAny(password, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") || !strings.ContainsAny(password, "!@#") {
+		t.Fatalf("password %v does not match the spec", password)
+	}
+}
+
+func TestGenPassDisabledClasses(t *testing.T) {
+	keygen := &KeyGen{rand.Reader}
+
+	// the legacy algorithm samples only the allowed characters, otherwise long
+	// passwords without a class are practically never generated
+	for _, length := range []int{64, 256} {
+		for _, spec := range []*PasswordSpec{
+			{Length: length, Upper: 3, Lower: 3, Digits: 1},
+			{Length: length, Upper: 0, Lower: 3, Digits: 1, Special: 1},
+			{Length: length, Upper: 3, Lower: 3, Digits: 1, Special: 1, AllowedSpecial: "!"},
+			{Length: length, Upper: 3, Lower: 3, Digits: 1, Special: 1, ExcludeAmbiguous: true},
+		} {
+			for i := 0; i < 100; i++ {
+				password, err := keygen.GeneratePassword(spec)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if len(password) != spec.Length || !spec.Compliant(password) {
+					t.Fatalf("password %v does not match the spec %+v", password, spec)
+				}
+			}
+		}
+	}
+}
+
+func TestGenPassTooStrict(t *testing.T) {
+	keygen := &KeyGen{rand.Reader}
+
+	// the legacy algorithm would practically never get these passwords
+	for _, spec := range []*PasswordSpec{
+		{Length: 64, Upper: 40, Lower: 20, Digits: 1, Special: 1},
+		{Length: 40, Upper: 1, Lower: 1, Digits: 30, Special: 0},
+	} {
+		_, err := keygen.GeneratePassword(spec)
+		if err != ErrPasswordSpecTooStrict {
+			t.Fatalf("unexpected error %v for spec %+v", err, spec)
+		}
+
+		spec.Version = PasswordV1
+		password, err := keygen.GeneratePassword(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(password) != spec.Length || !spec.Compliant(password) {
+			t.Fatalf("password %v does not match the spec %+v", password, spec)
+		}
+	}
+}
+
+func TestGenPassV1(t *testing.T) {
+	keygen := &KeyGen{rand.Reader}
+
+	for _, spec := range []*PasswordSpec{
+		{Length: 4, Upper: 1, Lower: 1, Digits: 1, Special: 1, Version: PasswordV1},
+		{Length: 5, Upper: 0, Lower: 0, Digits: 4, Special: 1, AllowedSpecial: "_", Version: PasswordV1},
+		{Length: 32, Upper: 3, Lower: 3, Digits: 1, Special: 1, AllowedSpecial: "!@#", Version: PasswordV1},
+	} {
+		for i := 0; i < 1000; i++ {
+			password, err := keygen.GeneratePassword(spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(password) != spec.Length || !spec.Compliant(password) {
+				t.Fatalf("password %v does not match the spec %+v", password, spec)
+			}
+		}
+	}
+
+	_, err := keygen.GeneratePassword(&PasswordSpec{Length: 16, Upper: 1, Version: 2})
+	if err == nil {
+		t.Fatal("generated password with unknown algorithm version")
+	}
+}
+
+func TestGetPassV1(t *testing.T) {
+	// the algorithm should not change, otherwise passwords for existing realms change
+	password, err := GetPass("pass1", "example.com", nil, &PasswordSpec{Length: 16, Upper: 3, Lower: 3, Digits: 2, Special: 1, Version: PasswordV1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if password != `2*9xRF'l/8>zQH[|` {
+		t.Fatalf("unexpected password %v", password)
+	}
+}
+
+func TestCustomAlphabet(t *testing.T) {
+	keygen := &KeyGen{rand.Reader}
+
+	var cjk []rune
+	for c := rune(0x4e00); c < 0x4e00+1000; c++ {
+		cjk = append(cjk, c)
+	}
+
+	for _, version := range []int{PasswordV0, PasswordV1} {
+		for _, spec := range []*PasswordSpec{
+			{Length: 12, Upper: 2, Lower: 2, Digits: 1, Alphabet: "абвгдежзийклмнопрстуфхцчшщъыьэюяАБВГДЕЖЗИЙКЛМНОПРСТУФХЦЧШЩЪЫЬЭЮЯ0123456789"},
+			{Length: 16, Upper: 1, Lower: 1, Digits: 1, Special: 1, ExcludeAmbiguous: true},
+			{Length: 8, Alphabet: string(cjk)},
+		} {
+			spec.Version = version
+			alphabet := spec.Alphabet
+			if alphabet == "" {
+				alphabet = chars
+			}
+
+			for i := 0; i < 100; i++ {
+				password, err := keygen.GeneratePassword(spec)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if len([]rune(password)) != spec.Length || !spec.Compliant(password) {
+					t.Fatalf("password %v does not match the spec %+v", password, spec)
+				}
+
+				for _, c := range password {
+					if !strings.ContainsRune(alphabet, c) || (spec.ExcludeAmbiguous && strings.ContainsRune(ambiguous, c)) {
+						t.Fatalf("password %v has character %q not from the alphabet", password, c)
+					}
+				}
+			}
+		}
+	}
+
+	for _, spec := range []*PasswordSpec{
+		{Length: 10, Lower: 1, Alphabet: "ABC"},
+		{Length: 10, Lower: 1, Alphabet: "abc\n"},
+		{Length: 10, Digits: 1, Alphabet: "01", ExcludeAmbiguous: true},
+	} {
+		if spec.Validate() == nil {
+			t.Fatalf("unsatisfiable spec %+v is valid", spec)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package gokey
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// below code implements Kerberos string-to-key functions as described in RFC
+// 3962 and RFC 8009 and the MIT keytab file format version 0x502
+
+// Kerberos encryption types
+const (
+	EncTypeAES128SHA1   = 17
+	EncTypeAES256SHA1   = 18
+	EncTypeAES256SHA384 = 20
+)
+
+const keytabVersion = 0x502
+
+// KRB5_NT_PRINCIPAL
+const krb5NTPrincipal = 1
+
+// KeytabSpec describes the keytab of a service principal
+type KeytabSpec struct {
+	// Principal is the full principal name, for example
+	// HTTP/www.example.com@EXAMPLE.COM
+	Principal string
+	// Salt overrides the default salt (realm followed by the principal name
+	// components), which is needed for some Active Directory accounts
+	Salt string
+	KVNO uint32
+	// Timestamp is stored in every keytab entry. The zero value is stored as
+	// zero, so the keytab is reproducible
+	Timestamp time.Time
+	// EncTypes are the encryption types of the keys in the keytab
+	EncTypes []int
+}
+
+// parsePrincipal splits the principal name into components and the realm
+func parsePrincipal(principal string) ([]string, string, error) {
+	at := strings.LastIndex(principal, "@")
+	if at <= 0 || at == len(principal)-1 || strings.Contains(principal, "\\") {
+		return nil, "", fmt.Errorf("invalid Kerberos principal %q", principal)
+	}
+
+	components := strings.Split(principal[:at], "/")
+	for _, c := range components {
+		if c == "" {
+			return nil, "", fmt.Errorf("invalid Kerberos principal %q", principal)
+		}
+	}
+
+	return components, principal[at+1:], nil
+}
+
+// nfold implements n-fold operation from RFC 3961 section 5.1 (port of the
+// MIT krb5 implementation). Sizes are in bytes.
+func nfold(in []byte, outLen int) []byte {
+	inLen := len(in)
+
+	a, b := outLen, inLen
+	for b != 0 {
+		a, b = b, a%b
+	}
+	lcm := outLen * inLen / a
+
+	out := make([]byte, outLen)
+	carry := 0
+	for i := lcm - 1; i >= 0; i-- {
+		msbit := ((inLen << 3) - 1 + ((inLen<<3)+13)*(i/inLen) + ((inLen - i%inLen) << 3)) % (inLen << 3)
+		carry += ((int(in[(inLen-1-(msbit>>3))%inLen])<<8 | int(in[(inLen-(msbit>>3))%inLen])) >> uint((msbit&7)+1)) & 0xff
+		carry += int(out[i%outLen])
+		out[i%outLen] = byte(carry)
+		carry >>= 8
+	}
+
+	if carry != 0 {
+		for i := outLen - 1; i >= 0; i-- {
+			carry += int(out[i])
+			out[i] = byte(carry)
+			carry >>= 8
+		}
+	}
+
+	return out
+}
+
+// aesDerive implements DK(key, constant) for AES encryption types from RFC
+// 3962 (random-to-key is the identity function for AES)
+func aesDerive(key, constant []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	state := nfold(constant, aes.BlockSize)
+	var derived []byte
+	for len(derived) < len(key) {
+		block.Encrypt(state, state)
+		derived = append(derived, state...)
+	}
+
+	return derived[:len(key)], nil
+}
+
+// KerberosKey runs the string-to-key function of the encryption type with the
+// default number of iterations
+func KerberosKey(encType int, password, salt string) ([]byte, error) {
+	return kerberosKey(encType, password, salt, 0)
+}
+
+func kerberosKey(encType int, password, salt string, iterations int) ([]byte, error) {
+	switch encType {
+	case EncTypeAES128SHA1, EncTypeAES256SHA1:
+		size := 16
+		if encType == EncTypeAES256SHA1 {
+			size = 32
+		}
+
+		if iterations == 0 {
+			iterations = 4096
+		}
+
+		tkey := pbkdf2.Key([]byte(password), []byte(salt), iterations, size, sha1.New)
+		return aesDerive(tkey, []byte("kerberos"))
+	case EncTypeAES256SHA384:
+		if iterations == 0 {
+			iterations = 32768
+		}
+
+		saltp := append([]byte("aes256-cts-hmac-sha384-192\x00"), salt...)
+		tkey := pbkdf2.Key([]byte(password), saltp, iterations, 32, sha512.New384)
+
+		// KDF-HMAC-SHA2(tkey, "kerberos", 256)
+		mac := hmac.New(sha512.New384, tkey)
+		mac.Write([]byte("\x00\x00\x00\x01kerberos\x00\x00\x00\x01\x00"))
+		return mac.Sum(nil)[:32], nil
+	}
+
+	return nil, fmt.Errorf("unsupported Kerberos encryption type %v", encType)
+}
+
+func writeCountedString(b *bytes.Buffer, s []byte) {
+	binary.Write(b, binary.BigEndian, uint16(len(s)))
+	b.Write(s)
+}
+
+// EncodeToKeytab writes the keytab file with the keys derived from the service
+// password
+func EncodeToKeytab(servicePassword string, spec *KeytabSpec, w io.Writer) error {
+	components, realm, err := parsePrincipal(spec.Principal)
+	if err != nil {
+		return err
+	}
+
+	if len(spec.EncTypes) == 0 {
+		return errors.New("no Kerberos encryption types provided")
+	}
+
+	salt := spec.Salt
+	if salt == "" {
+		salt = realm + strings.Join(components, "")
+	}
+
+	var timestamp uint32
+	if !spec.Timestamp.IsZero() {
+		timestamp = uint32(spec.Timestamp.Unix())
+	}
+
+	var b bytes.Buffer
+	binary.Write(&b, binary.BigEndian, uint16(keytabVersion))
+
+	for _, encType := range spec.EncTypes {
+		key, err := KerberosKey(encType, servicePassword, salt)
+		if err != nil {
+			return err
+		}
+
+		var entry bytes.Buffer
+		binary.Write(&entry, binary.BigEndian, uint16(len(components)))
+		writeCountedString(&entry, []byte(realm))
+		for _, c := range components {
+			writeCountedString(&entry, []byte(c))
+		}
+		binary.Write(&entry, binary.BigEndian, uint32(krb5NTPrincipal))
+		binary.Write(&entry, binary.BigEndian, timestamp)
+		entry.WriteByte(byte(spec.KVNO))
+		binary.Write(&entry, binary.BigEndian, uint16(encType))
+		writeCountedString(&entry, key)
+		// optional 32-bit key version number, which supersedes the 8-bit one
+		binary.Write(&entry, binary.BigEndian, spec.KVNO)
+
+		binary.Write(&b, binary.BigEndian, int32(entry.Len()))
+		b.Write(entry.Bytes())
+	}
+
+	_, err = w.Write(b.Bytes())
+	return err
+}
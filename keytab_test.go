@@ -0,0 +1,101 @@
+package gokey
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+)
+
+func TestNfold(t *testing.T) {
+	// RFC 3961 appendix A.1
+	for _, test := range []struct {
+		in     string
+		size   int
+		folded string
+	}{
+		{"012345", 8, "be072631276b1955"},
+		{"password", 7, "78a07b6caf85fa"},
+		{"kerberos", 16, "6b65726265726f737b9b5b2b93132b93"},
+		{"Q", 21, "518a54a215a8452a518a54a215a8452a518a54a215"},
+	} {
+		if folded := hex.EncodeToString(nfold([]byte(test.in), test.size)); folded != test.folded {
+			t.Fatalf("invalid n-fold of %q: %v", test.in, folded)
+		}
+	}
+}
+
+func TestKerberosKey(t *testing.T) {
+	salt, _ := hex.DecodeString("10df9dd783e5bc8acea1730e74355f61")
+
+	for _, test := range []struct {
+		encType    int
+		salt       string
+		iterations int
+		key        string
+	}{
+		// RFC 3962 appendix B
+		{EncTypeAES128SHA1, "ATHENA.MIT.EDUraeburn", 1, "42263c6e89f4fc28b8df68ee09799f15"},
+		{EncTypeAES256SHA1, "ATHENA.MIT.EDUraeburn", 1, "fe697b52bc0d3ce14432ba036a92e65bbb52280990a2fa27883998d72af30161"},
+		{EncTypeAES128SHA1, "ATHENA.MIT.EDUraeburn", 1200, "4c01cd46d632d01e6dbe230a01ed642a"},
+		// RFC 8009 appendix A
+		{EncTypeAES256SHA384, string(salt) + "ATHENA.MIT.EDUraeburn", 0, "45bd806dbf6a833a9cffc1c94589a222367a79bc21c413718906e9f578a78467"},
+	} {
+		key, err := kerberosKey(test.encType, "password", test.salt, test.iterations)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if hex.EncodeToString(key) != test.key {
+			t.Fatalf("invalid key for encryption type %v: %x", test.encType, key)
+		}
+	}
+
+	_, err := KerberosKey(23, "password", "EXAMPLE.COMuser")
+	if err == nil {
+		t.Fatal("allowed unsupported encryption type")
+	}
+}
+
+func TestKeytab(t *testing.T) {
+	spec := &KeytabSpec{
+		Principal: "HTTP/www.example.com@EXAMPLE.COM",
+		KVNO:      300,
+		EncTypes:  []int{EncTypeAES256SHA1, EncTypeAES128SHA1},
+	}
+
+	var b bytes.Buffer
+	err := EncodeToKeytab("service-password", spec, &b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keytab := b.Bytes()
+	if binary.BigEndian.Uint16(keytab) != 0x502 {
+		t.Fatal("invalid keytab version")
+	}
+
+	size := int(binary.BigEndian.Uint32(keytab[2:]))
+	entry := keytab[6 : 6+size]
+	key, err := KerberosKey(EncTypeAES256SHA1, "service-password", "EXAMPLE.COMHTTPwww.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// components, realm, HTTP, www.example.com, name type, timestamp, kvno, enctype, key length
+	prefix := "\x00\x02\x00\x0bEXAMPLE.COM\x00\x04HTTP\x00\x0fwww.example.com\x00\x00\x00\x01\x00\x00\x00\x00\x2c\x00\x12\x00\x20"
+	if !bytes.Equal(entry, append(append([]byte(prefix), key...), 0, 0, 1, 0x2c)) {
+		t.Fatal("invalid keytab entry")
+	}
+
+	if len(keytab) != 6+size+4+size-16 {
+		t.Fatal("invalid keytab size")
+	}
+
+	for _, principal := range []string{"HTTP/www.example.com", "@EXAMPLE.COM", "HTTP//www@EXAMPLE.COM"} {
+		err = EncodeToKeytab("service-password", &KeytabSpec{Principal: principal, EncTypes: spec.EncTypes}, &b)
+		if err == nil {
+			t.Fatalf("allowed invalid principal %q", principal)
+		}
+	}
+}
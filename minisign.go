@@ -0,0 +1,182 @@
+package gokey
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/ed25519"
+)
+
+// below code implements minisign key and signature file formats as described in
+// https://jedisct1.github.io/minisign/ and minisign.h from
+// https://github.com/jedisct1/minisign
+// secret keys are stored unencrypted (same as "minisign -G -W")
+
+// SigningKey is an ed25519 key with a key ID as used by minisign and signify
+type SigningKey struct {
+	ID  [8]byte
+	Key ed25519.PrivateKey
+}
+
+// GetSigningKey derives the ed25519 key and the key ID for the realm. The key
+// is derived for the realm with a separate "minisign" component, so signatures
+// over arbitrary files can not be used as signatures of other protocols.
+func GetSigningKey(password, realm string, seed []byte, allowUnsafe bool) (*SigningKey, error) {
+	key, err := GetKey(password, protocolRealm(realm, "minisign"), seed, ED25519, allowUnsafe)
+	if err != nil {
+		return nil, err
+	}
+
+	rng, err := GetRaw(password, protocolRealm(realm, "minisign-keyid"), seed, allowUnsafe)
+	if err != nil {
+		return nil, err
+	}
+
+	sk := &SigningKey{Key: *key.(*ed25519.PrivateKey)}
+	_, err = io.ReadFull(rng, sk.ID[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return sk, nil
+}
+
+// public returns the raw ed25519 public key
+func (key *SigningKey) public() []byte {
+	return key.Key.Public().(ed25519.PublicKey)
+}
+
+// minisign displays key IDs as little-endian 64-bit numbers
+func (key *SigningKey) minisignID() string {
+	return fmt.Sprintf("%016X", binary.LittleEndian.Uint64(key.ID[:]))
+}
+
+func writeKeyFile(w io.Writer, comment string, data ...[]byte) error {
+	_, err := fmt.Fprintf(w, "untrusted comment: %s\n%s\n", comment, base64.StdEncoding.EncodeToString(bytes.Join(data, nil)))
+	return err
+}
+
+// EncodeToMinisign writes the unencrypted minisign secret key file
+func EncodeToMinisign(key *SigningKey, w io.Writer) error {
+	// checksum = BLAKE2b-256(sig_alg || key_id || secret_key)
+	h, err := blake2b.New256(nil)
+	if err != nil {
+		return err
+	}
+	h.Write([]byte("Ed"))
+	h.Write(key.ID[:])
+	h.Write(key.Key)
+
+	// sig_alg, kdf_alg (none), chk_alg, kdf_salt, kdf_opslimit, kdf_memlimit
+	header := append([]byte("Ed\x00\x00B2"), make([]byte, 32+8+8)...)
+
+	return writeKeyFile(w, "minisign secret key", header, key.ID[:], key.Key, h.Sum(nil))
+}
+
+// EncodePublicToMinisign writes the minisign public key file
+func EncodePublicToMinisign(key *SigningKey, w io.Writer) error {
+	return writeKeyFile(w, "minisign public key "+key.minisignID(), []byte("Ed"), key.ID[:], key.public())
+}
+
+// MinisignSign writes the minisign signature file for the message. The
+// message is prehashed with BLAKE2b-512 as done by minisign by default.
+func MinisignSign(key *SigningKey, message io.Reader, trustedComment string, w io.Writer) error {
+	if strings.ContainsAny(trustedComment, "\r\n") {
+		return errors.New("trusted comment should be a single line")
+	}
+
+	h, err := blake2b.New512(nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(h, message)
+	if err != nil {
+		return err
+	}
+
+	sig := ed25519.Sign(key.Key, h.Sum(nil))
+	globalSig := ed25519.Sign(key.Key, append(append([]byte{}, sig...), trustedComment...))
+
+	err = writeKeyFile(w, "signature from minisign secret key", []byte("ED"), key.ID[:], sig)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "trusted comment: %s\n%s\n", trustedComment, base64.StdEncoding.EncodeToString(globalSig))
+	return err
+}
+
+// MinisignVerify verifies the minisign signature of the message and returns
+// the trusted comment of the signature
+func MinisignVerify(key *SigningKey, message io.Reader, signature []byte) (string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(signature))
+	for scanner.Scan() {
+		lines = append(lines, strings.TrimRight(scanner.Text(), "\r"))
+	}
+
+	if len(lines) < 4 || !strings.HasPrefix(lines[0], "untrusted comment: ") || !strings.HasPrefix(lines[2], "trusted comment: ") {
+		return "", errors.New("invalid minisign signature file")
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil {
+		return "", err
+	}
+
+	globalSig, err := base64.StdEncoding.DecodeString(lines[3])
+	if err != nil {
+		return "", err
+	}
+
+	if len(sig) != 2+8+ed25519.SignatureSize || len(globalSig) != ed25519.SignatureSize {
+		return "", errors.New("invalid minisign signature file")
+	}
+
+	if !bytes.Equal(sig[2:10], key.ID[:]) {
+		return "", fmt.Errorf("signature key ID %016X does not match key ID %v", binary.LittleEndian.Uint64(sig[2:10]), key.minisignID())
+	}
+
+	var signed []byte
+	switch string(sig[:2]) {
+	case "ED":
+		h, err := blake2b.New512(nil)
+		if err != nil {
+			return "", err
+		}
+
+		_, err = io.Copy(h, message)
+		if err != nil {
+			return "", err
+		}
+		signed = h.Sum(nil)
+	case "Ed":
+		// legacy signatures of the whole message
+		signed, err = ioutil.ReadAll(message)
+		if err != nil {
+			return "", err
+		}
+	default:
+		return "", errors.New("unsupported minisign signature algorithm")
+	}
+
+	if !ed25519.Verify(key.public(), signed, sig[10:]) {
+		return "", errors.New("signature verification failed")
+	}
+
+	trustedComment := strings.TrimPrefix(lines[2], "trusted comment: ")
+	if !ed25519.Verify(key.public(), append(append([]byte{}, sig[10:]...), trustedComment...), globalSig) {
+		return "", errors.New("trusted comment verification failed")
+	}
+
+	return trustedComment, nil
+}
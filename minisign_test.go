@@ -0,0 +1,152 @@
+package gokey
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+func decodeKeyFile(t *testing.T, file string, comment string) []byte {
+	lines := strings.Split(file, "\n")
+	if len(lines) < 2 || lines[0] != "untrusted comment: "+comment {
+		t.Fatalf("invalid key file comment %q", lines[0])
+	}
+
+	data, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+func TestGetSigningKey(t *testing.T) {
+	key1, err := GetSigningKey("pass1", "example.com", nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key2, err := GetSigningKey("pass1", "example2.com", nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if key1.ID == key2.ID {
+		t.Fatal("key IDs match for different realms")
+	}
+
+	// the signing key must not be shared with other protocols
+	key, err := GetKey("pass1", "example.com", nil, ED25519, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Equal(key.(*ed25519.PrivateKey).Seed(), key1.Key.Seed()) {
+		t.Fatal("signing key matches ed25519 key for the same realm")
+	}
+
+	// nor derived for a crafted realm
+	key, err = GetKey("pass1", "example.com-minisign", nil, ED25519, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Equal(key.(*ed25519.PrivateKey).Seed(), key1.Key.Seed()) {
+		t.Fatal("signing key matches ed25519 key for a crafted realm")
+	}
+
+	rng, err := GetRaw("pass1", "example.com-minisign-keyid", nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var id [8]byte
+	_, err = io.ReadFull(rng, id[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if id == key1.ID {
+		t.Fatal("key ID matches raw output for a crafted realm")
+	}
+
+	service, err := GetOnionService("pass1", "example.com", nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.HasSuffix(service.PublicKey, key1.Key.Public().(ed25519.PublicKey)) {
+		t.Fatal("signing key matches onion service key for the same realm")
+	}
+
+	_, err = GetSigningKey("pass1", "example.com", nil, false)
+	if err == nil {
+		t.Fatal("allowed unsafe key generation")
+	}
+}
+
+func TestMinisign(t *testing.T) {
+	key, err := GetSigningKey("pass1", "example.com", nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var secret, public, sig strings.Builder
+	err = EncodeToMinisign(key, &secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = EncodePublicToMinisign(key, &public)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data := decodeKeyFile(t, secret.String(), "minisign secret key"); len(data) != 158 || !bytes.Equal(data[54:62], key.ID[:]) {
+		t.Fatal("invalid minisign secret key")
+	}
+
+	if data := decodeKeyFile(t, public.String(), "minisign public key "+key.minisignID()); len(data) != 42 || string(data[:2]) != "Ed" {
+		t.Fatal("invalid minisign public key")
+	}
+
+	message := []byte("release artifact")
+	err = MinisignSign(key, bytes.NewReader(message), "file:artifact.tar.gz", &sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	comment, err := MinisignVerify(key, bytes.NewReader(message), []byte(sig.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if comment != "file:artifact.tar.gz" {
+		t.Fatal("trusted comment does not match")
+	}
+
+	_, err = MinisignVerify(key, strings.NewReader("tampered artifact"), []byte(sig.String()))
+	if err == nil {
+		t.Fatal("verified signature of a different message")
+	}
+
+	tampered := strings.Replace(sig.String(), "file:artifact.tar.gz", "file:other.tar.gz", 1)
+	_, err = MinisignVerify(key, bytes.NewReader(message), []byte(tampered))
+	if err == nil {
+		t.Fatal("verified signature with a tampered trusted comment")
+	}
+
+	other, err := GetSigningKey("pass1", "example2.com", nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = MinisignVerify(other, bytes.NewReader(message), []byte(sig.String()))
+	if err == nil {
+		t.Fatal("verified signature with a different key")
+	}
+}
@@ -0,0 +1,85 @@
+package gokey
+
+import (
+	"crypto"
+	"crypto/sha512"
+	"encoding/base32"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/ed25519"
+	"golang.org/x/crypto/sha3"
+)
+
+// below code implements Tor v3 onion service key files and addresses according
+// to https://spec.torproject.org/rend-spec/encoding-onion-addresses.html
+// the key files are compatible with the ones Tor creates in HiddenServiceDir
+
+const (
+	onionSecretKeyHeader = "== ed25519v1-secret: type0 ==\x00\x00\x00"
+	onionPublicKeyHeader = "== ed25519v1-public: type0 ==\x00\x00\x00"
+	onionVersion         = 3
+)
+
+// OnionService holds the contents of Tor v3 onion service key files
+type OnionService struct {
+	// SecretKey is the content of hs_ed25519_secret_key file
+	SecretKey []byte
+	// PublicKey is the content of hs_ed25519_public_key file
+	PublicKey []byte
+	// Hostname is the onion address of the service including the .onion
+	// suffix
+	Hostname string
+}
+
+func onionAddress(pub ed25519.PublicKey) string {
+	// CHECKSUM = H(".onion checksum" | PUBKEY | VERSION)[:2]
+	h := sha3.New256()
+	h.Write([]byte(".onion checksum"))
+	h.Write(pub)
+	h.Write([]byte{onionVersion})
+	checksum := h.Sum(nil)
+
+	// onion_address = base32(PUBKEY | CHECKSUM | VERSION) + ".onion"
+	address := append([]byte{}, pub...)
+	address = append(address, checksum[:2]...)
+	address = append(address, onionVersion)
+
+	return strings.ToLower(base32.StdEncoding.EncodeToString(address)) + ".onion"
+}
+
+// NewOnionService converts an ed25519 private key to Tor v3 onion service key
+// files
+func NewOnionService(key crypto.PrivateKey) (*OnionService, error) {
+	edKey, ok := key.(*ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("onion service keys are not supported for key type %T", key)
+	}
+
+	// Tor stores the expanded secret key: clamped scalar followed by the
+	// nonce generation key
+	expanded := sha512.Sum512(edKey.Seed())
+	expanded[0] &= 248
+	expanded[31] &= 63
+	expanded[31] |= 64
+
+	pub := edKey.Public().(ed25519.PublicKey)
+
+	return &OnionService{
+		SecretKey: append([]byte(onionSecretKeyHeader), expanded[:]...),
+		PublicKey: append([]byte(onionPublicKeyHeader), pub...),
+		Hostname:  onionAddress(pub),
+	}, nil
+}
+
+// GetOnionService derives the ed25519 key for the realm and returns Tor v3
+// onion service key files for it. The key is derived for the realm with a
+// separate "onion" component, so it is not shared with other protocols.
+func GetOnionService(password, realm string, seed []byte, allowUnsafe bool) (*OnionService, error) {
+	key, err := GetKey(password, protocolRealm(realm, "onion"), seed, ED25519, allowUnsafe)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewOnionService(key)
+}
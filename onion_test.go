@@ -0,0 +1,84 @@
+package gokey
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/base32"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+func TestOnionAddress(t *testing.T) {
+	// DuckDuckGo onion service
+	known := "duckduckgogg42xjoc72x3sjasowoarfbgcmvfimaftt6twagswzczad.onion"
+
+	decoded, err := base32.StdEncoding.DecodeString(strings.ToUpper(strings.TrimSuffix(known, ".onion")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if address := onionAddress(ed25519.PublicKey(decoded[:32])); address != known {
+		t.Fatalf("onion address %v does not match the expected %v", address, known)
+	}
+}
+
+func TestOnionService(t *testing.T) {
+	seed, err := GenerateEncryptedKeySeed("pass1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	service, err := GetOnionService("pass1", "example.onion", seed, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(service.SecretKey) != 96 || !bytes.HasPrefix(service.SecretKey, []byte("== ed25519v1-secret: type0 ==")) {
+		t.Fatal("invalid secret key file")
+	}
+
+	if len(service.PublicKey) != 64 || !bytes.HasPrefix(service.PublicKey, []byte("== ed25519v1-public: type0 ==")) {
+		t.Fatal("invalid public key file")
+	}
+
+	if len(service.Hostname) != 62 || onionAddress(service.PublicKey[32:]) != service.Hostname {
+		t.Fatal("invalid hostname")
+	}
+
+	key, err := GetKey("pass1", protocolRealm("example.onion", "onion"), seed, ED25519, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expanded := sha512.Sum512(key.(*ed25519.PrivateKey).Seed())
+	if !bytes.Equal(service.SecretKey[64:], expanded[32:]) {
+		t.Fatal("invalid nonce generation key in secret key file")
+	}
+
+	// the onion service key must not be shared with other protocols
+	key, err = GetKey("pass1", "example.onion", seed, ED25519, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.HasSuffix(service.PublicKey, key.(*ed25519.PrivateKey).Public().(ed25519.PublicKey)) {
+		t.Fatal("onion service key matches ed25519 key for the same realm")
+	}
+
+	// nor derived for a crafted realm
+	key, err = GetKey("pass1", "example.onion-onion", seed, ED25519, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.HasSuffix(service.PublicKey, key.(*ed25519.PrivateKey).Public().(ed25519.PublicKey)) {
+		t.Fatal("onion service key matches ed25519 key for a crafted realm")
+	}
+
+	_, err = GetOnionService("pass1", "example.onion", nil, false)
+	if err == nil {
+		t.Fatal("allowed unsafe key generation")
+	}
+}
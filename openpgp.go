@@ -0,0 +1,469 @@
+package gokey
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"time"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+// below code implements OpenPGP transferable secret keys as defined in
+// https://www.rfc-editor.org/rfc/rfc9580.html (v6 keys) and
+// https://www.rfc-editor.org/rfc/rfc4880.html with the curve25519 extensions
+// from https://datatracker.ietf.org/doc/html/draft-ietf-openpgp-rfc4880bis-10
+// (v4 keys)
+// keys are stored unencrypted and all signatures are made at the key creation
+// time, so the output is fully reproducible
+
+// p.9.1 https://www.rfc-editor.org/rfc/rfc9580.html
+const (
+	pgpAlgoRSA         = 1
+	pgpAlgoECDH        = 18
+	pgpAlgoEdDSALegacy = 22
+	pgpAlgoX25519      = 25
+	pgpAlgoEd25519     = 27
+)
+
+// p.5.2.1 https://www.rfc-editor.org/rfc/rfc9580.html
+const (
+	pgpSigDirectKey      = 0x1f
+	pgpSigPositiveCert   = 0x13
+	pgpSigSubkeyBinding  = 0x18
+	pgpHashSHA256        = 8
+	pgpSymAES128         = 7
+	pgpKeyFlagsCertSign  = 0x03
+	pgpKeyFlagsEncrypt   = 0x0c
+	pgpFeaturesSEIPDv1   = 0x01
+	pgpFeaturesSEIPDv2   = 0x08
+	pgpSubCreationTime   = 2
+	pgpSubPrefSym        = 11
+	pgpSubIssuer         = 16
+	pgpSubPrefHash       = 21
+	pgpSubPrimaryUID     = 25
+	pgpSubKeyFlags       = 27
+	pgpSubFeatures       = 30
+	pgpSubIssuerFpr      = 33
+	pgpSubPrefAEAD       = 39
+	pgpTagSignature      = 2
+	pgpTagSecretKey      = 5
+	pgpTagPublicKey      = 6
+	pgpTagSecretSubkey   = 7
+	pgpTagUserID         = 13
+	pgpTagPublicSubkey   = 14
+	pgpSaltLength        = 16
+	pgpV4FingerprintType = 0x99
+	pgpV6FingerprintType = 0x9b
+)
+
+// curve OIDs used by v4 curve25519 keys
+// p.9.2 https://datatracker.ietf.org/doc/html/draft-ietf-openpgp-rfc4880bis-10
+var (
+	pgpOidEd25519    = []byte{0x2b, 0x06, 0x01, 0x04, 0x01, 0xda, 0x47, 0x0f, 0x01}
+	pgpOidCurve25519 = []byte{0x2b, 0x06, 0x01, 0x04, 0x01, 0x97, 0x55, 0x01, 0x05, 0x01}
+)
+
+type OpenPGPSpec struct {
+	UserID  string
+	Created time.Time
+	// Version is the OpenPGP key version: 4 (default) or 6
+	Version int
+}
+
+type pgpKey struct {
+	key     crypto.PrivateKey
+	version int
+	algo    byte
+	public  []byte
+	secret  []byte
+}
+
+func pgpMpi(b []byte) []byte {
+	n := new(big.Int).SetBytes(b)
+	mpi := make([]byte, 2, 2+len(b))
+	binary.BigEndian.PutUint16(mpi, uint16(n.BitLen()))
+	return append(mpi, n.Bytes()...)
+}
+
+func pgpLength(length int) []byte {
+	switch {
+	case length < 192:
+		return []byte{byte(length)}
+	case length < 8384:
+		length -= 192
+		return []byte{byte(length>>8) + 192, byte(length)}
+	}
+
+	return []byte{0xff, byte(length >> 24), byte(length >> 16), byte(length >> 8), byte(length)}
+}
+
+func pgpPacket(tag byte, body []byte) []byte {
+	packet := append([]byte{0xc0 | tag}, pgpLength(len(body))...)
+	return append(packet, body...)
+}
+
+func pgpSubpacket(kind byte, data ...byte) []byte {
+	sub := append(pgpLength(len(data)+1), kind)
+	return append(sub, data...)
+}
+
+func reverse(b []byte) []byte {
+	r := make([]byte, len(b))
+	for i := range b {
+		r[len(b)-1-i] = b[i]
+	}
+	return r
+}
+
+func newPgpKey(key crypto.PrivateKey, version int, created time.Time) (*pgpKey, error) {
+	var material, secret []byte
+	k := &pgpKey{key: key, version: version}
+
+	switch key := key.(type) {
+	case *ed25519.PrivateKey:
+		pub := key.Public().(ed25519.PublicKey)
+		if version == 6 {
+			k.algo = pgpAlgoEd25519
+			material = pub
+			secret = key.Seed()
+		} else {
+			k.algo = pgpAlgoEdDSALegacy
+			material = append([]byte{byte(len(pgpOidEd25519))}, pgpOidEd25519...)
+			material = append(material, pgpMpi(append([]byte{0x40}, pub...))...)
+			secret = pgpMpi(key.Seed())
+		}
+	case x25519PrivateKey:
+		pub, err := x25519PublicKey(key)
+		if err != nil {
+			return nil, err
+		}
+
+		if version == 6 {
+			k.algo = pgpAlgoX25519
+			material = pub
+			secret = key
+		} else {
+			k.algo = pgpAlgoECDH
+			material = append([]byte{byte(len(pgpOidCurve25519))}, pgpOidCurve25519...)
+			material = append(material, pgpMpi(append([]byte{0x40}, pub...))...)
+			// KDF parameters: reserved, SHA256, AES128
+			material = append(material, 3, 1, pgpHashSHA256, pgpSymAES128)
+			// curve25519 secret is stored in big-endian, that is reversed from native
+			secret = pgpMpi(reverse(key))
+		}
+	case *rsa.PrivateKey:
+		k.algo = pgpAlgoRSA
+		material = append(pgpMpi(key.N.Bytes()), pgpMpi(big.NewInt(int64(key.E)).Bytes())...)
+
+		// OpenPGP requires p < q and u = p^-1 mod q
+		p, q := key.Primes[0], key.Primes[1]
+		if p.Cmp(q) > 0 {
+			p, q = q, p
+		}
+		u := new(big.Int).ModInverse(p, q)
+
+		for _, n := range []*big.Int{key.D, p, q, u} {
+			secret = append(secret, pgpMpi(n.Bytes())...)
+		}
+	default:
+		return nil, fmt.Errorf("unable to encode key type %T", key)
+	}
+
+	k.public = []byte{byte(version), 0, 0, 0, 0, k.algo}
+	binary.BigEndian.PutUint32(k.public[1:5], uint32(created.Unix()))
+	if version == 6 {
+		k.public = append(k.public, 0, 0, 0, 0)
+		binary.BigEndian.PutUint32(k.public[6:], uint32(len(material)))
+	}
+	k.public = append(k.public, material...)
+
+	// unencrypted secret key material
+	k.secret = append([]byte{0}, secret...)
+	if version != 6 {
+		var checksum uint16
+		for _, b := range secret {
+			checksum += uint16(b)
+		}
+		k.secret = append(k.secret, byte(checksum>>8), byte(checksum))
+	}
+
+	return k, nil
+}
+
+// hashedKey returns the key representation used in fingerprints and signatures
+func (k *pgpKey) hashedKey() []byte {
+	if k.version == 6 {
+		prefix := []byte{pgpV6FingerprintType, 0, 0, 0, 0}
+		binary.BigEndian.PutUint32(prefix[1:], uint32(len(k.public)))
+		return append(prefix, k.public...)
+	}
+
+	return append([]byte{pgpV4FingerprintType, byte(len(k.public) >> 8), byte(len(k.public))}, k.public...)
+}
+
+func (k *pgpKey) fingerprint() []byte {
+	if k.version == 6 {
+		fpr := sha256.Sum256(k.hashedKey())
+		return fpr[:]
+	}
+
+	fpr := sha1.Sum(k.hashedKey())
+	return fpr[:]
+}
+
+func (k *pgpKey) sign(rng io.Reader, sigType byte, hashed []byte, data ...[]byte) ([]byte, error) {
+	fpr := k.fingerprint()
+	hashed = append(hashed, pgpSubpacket(pgpSubIssuerFpr, append([]byte{byte(k.version)}, fpr...)...)...)
+
+	var unhashed, salt []byte
+	sig := []byte{byte(k.version), sigType, k.algo, pgpHashSHA256}
+	if k.version == 6 {
+		salt = make([]byte, pgpSaltLength)
+		_, err := io.ReadFull(rng, salt)
+		if err != nil {
+			return nil, err
+		}
+
+		sig = append(sig, 0, 0, byte(len(hashed)>>8), byte(len(hashed)))
+	} else {
+		unhashed = pgpSubpacket(pgpSubIssuer, fpr[len(fpr)-8:]...)
+		sig = append(sig, byte(len(hashed)>>8), byte(len(hashed)))
+	}
+	sig = append(sig, hashed...)
+
+	h := sha256.New()
+	h.Write(salt)
+	for _, d := range data {
+		h.Write(d)
+	}
+	h.Write(sig)
+	trailer := []byte{byte(k.version), 0xff, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(trailer[2:], uint32(len(sig)))
+	h.Write(trailer)
+	digest := h.Sum(nil)
+
+	if k.version == 6 {
+		sig = append(sig, 0, 0, byte(len(unhashed)>>8), byte(len(unhashed)))
+	} else {
+		sig = append(sig, byte(len(unhashed)>>8), byte(len(unhashed)))
+	}
+	sig = append(sig, unhashed...)
+	sig = append(sig, digest[:2]...)
+	if k.version == 6 {
+		sig = append(sig, byte(len(salt)))
+		sig = append(sig, salt...)
+	}
+
+	switch key := k.key.(type) {
+	case *ed25519.PrivateKey:
+		rs := ed25519.Sign(*key, digest)
+		if k.version == 6 {
+			sig = append(sig, rs...)
+		} else {
+			sig = append(sig, pgpMpi(rs[:32])...)
+			sig = append(sig, pgpMpi(rs[32:])...)
+		}
+	case *rsa.PrivateKey:
+		s, err := rsa.SignPKCS1v15(nil, key, crypto.SHA256, digest)
+		if err != nil {
+			return nil, err
+		}
+		sig = append(sig, pgpMpi(s)...)
+	default:
+		return nil, fmt.Errorf("unable to sign with key type %T", k.key)
+	}
+
+	return pgpPacket(pgpTagSignature, sig), nil
+}
+
+func encodeOpenPGP(primary, subkey crypto.PrivateKey, spec *OpenPGPSpec, secret bool, w io.Writer) error {
+	version := spec.Version
+	if version == 0 {
+		version = 4
+	}
+	if version != 4 && version != 6 {
+		return fmt.Errorf("unsupported OpenPGP key version %v", spec.Version)
+	}
+
+	if spec.UserID == "" {
+		return errors.New("no OpenPGP user ID provided")
+	}
+
+	if spec.Created.Unix() < 0 || spec.Created.Unix() > 0xffffffff {
+		return errors.New("invalid OpenPGP key creation time")
+	}
+
+	pk, err := newPgpKey(primary, version, spec.Created)
+	if err != nil {
+		return err
+	}
+
+	sk, err := newPgpKey(subkey, version, spec.Created)
+	if err != nil {
+		return err
+	}
+
+	// v6 signatures are salted, so derive the salt from the keys to keep the
+	// output reproducible
+	rng := NewDRNG(string(pk.secret), string(sk.secret))
+
+	created := make([]byte, 4)
+	binary.BigEndian.PutUint32(created, uint32(spec.Created.Unix()))
+
+	features := byte(pgpFeaturesSEIPDv1)
+	if version == 6 {
+		features |= pgpFeaturesSEIPDv2
+	}
+
+	prefs := pgpSubpacket(pgpSubCreationTime, created...)
+	prefs = append(prefs, pgpSubpacket(pgpSubKeyFlags, pgpKeyFlagsCertSign)...)
+	// AES256, AES192, AES128
+	prefs = append(prefs, pgpSubpacket(pgpSubPrefSym, 9, 8, 7)...)
+	// SHA512, SHA384, SHA256
+	prefs = append(prefs, pgpSubpacket(pgpSubPrefHash, 10, 9, 8)...)
+	prefs = append(prefs, pgpSubpacket(pgpSubFeatures, features)...)
+	if version == 6 {
+		// AES256-OCB, AES128-OCB
+		prefs = append(prefs, pgpSubpacket(pgpSubPrefAEAD, 9, 2, 7, 2)...)
+	}
+
+	primaryTag, subkeyTag := byte(pgpTagSecretKey), byte(pgpTagSecretSubkey)
+	primaryBody, subkeyBody := append(pk.public, pk.secret...), append(sk.public, sk.secret...)
+	if !secret {
+		primaryTag, subkeyTag = pgpTagPublicKey, pgpTagPublicSubkey
+		primaryBody, subkeyBody = pk.public, sk.public
+	}
+
+	var out bytes.Buffer
+	out.Write(pgpPacket(primaryTag, primaryBody))
+
+	uid := []byte(spec.UserID)
+	uidHeader := []byte{0xb4, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(uidHeader[1:], uint32(len(uid)))
+
+	var uidSig []byte
+	if version == 6 {
+		// v6 keys carry key flags and preferences in a direct key signature
+		directSig, err := pk.sign(rng, pgpSigDirectKey, prefs, pk.hashedKey())
+		if err != nil {
+			return err
+		}
+		out.Write(directSig)
+
+		hashed := pgpSubpacket(pgpSubCreationTime, created...)
+		hashed = append(hashed, pgpSubpacket(pgpSubPrimaryUID, 1)...)
+		uidSig, err = pk.sign(rng, pgpSigPositiveCert, hashed, pk.hashedKey(), uidHeader, uid)
+	} else {
+		uidSig, err = pk.sign(rng, pgpSigPositiveCert, append(prefs, pgpSubpacket(pgpSubPrimaryUID, 1)...), pk.hashedKey(), uidHeader, uid)
+	}
+	if err != nil {
+		return err
+	}
+
+	out.Write(pgpPacket(pgpTagUserID, uid))
+	out.Write(uidSig)
+
+	hashed := pgpSubpacket(pgpSubCreationTime, created...)
+	hashed = append(hashed, pgpSubpacket(pgpSubKeyFlags, pgpKeyFlagsEncrypt)...)
+	subkeySig, err := pk.sign(rng, pgpSigSubkeyBinding, hashed, pk.hashedKey(), sk.hashedKey())
+	if err != nil {
+		return err
+	}
+
+	out.Write(pgpPacket(subkeyTag, subkeyBody))
+	out.Write(subkeySig)
+
+	blockType := "PGP PRIVATE KEY BLOCK"
+	if !secret {
+		blockType = "PGP PUBLIC KEY BLOCK"
+	}
+
+	// v6 armor should not have a checksum
+	// p.6.1 https://www.rfc-editor.org/rfc/rfc9580.html
+	return pgpArmor(w, blockType, out.Bytes(), version != 6)
+}
+
+// p.6.1.1 https://www.rfc-editor.org/rfc/rfc9580.html
+func pgpCrc24(data []byte) uint32 {
+	crc := uint32(0xb704ce)
+	for _, b := range data {
+		crc ^= uint32(b) << 16
+		for i := 0; i < 8; i++ {
+			crc <<= 1
+			if crc&0x1000000 != 0 {
+				crc ^= 0x1864cfb
+			}
+		}
+	}
+
+	return crc & 0xffffff
+}
+
+func pgpArmor(w io.Writer, blockType string, data []byte, checksum bool) error {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "-----BEGIN %s-----\n\n", blockType)
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 64 {
+		fmt.Fprintln(&b, encoded[:64])
+		encoded = encoded[64:]
+	}
+	fmt.Fprintln(&b, encoded)
+	if checksum {
+		crc := pgpCrc24(data)
+		fmt.Fprintf(&b, "=%s\n", base64.StdEncoding.EncodeToString([]byte{byte(crc >> 16), byte(crc >> 8), byte(crc)}))
+	}
+	fmt.Fprintf(&b, "-----END %s-----\n", blockType)
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// EncodeToOpenPGP writes an ASCII-armored OpenPGP transferable secret key with
+// the certification/signing primary key, an encryption subkey and a user ID
+func EncodeToOpenPGP(primary, subkey crypto.PrivateKey, spec *OpenPGPSpec, w io.Writer) error {
+	return encodeOpenPGP(primary, subkey, spec, true, w)
+}
+
+// EncodePublicToOpenPGP writes the ASCII-armored OpenPGP transferable public
+// key matching the output of EncodeToOpenPGP
+func EncodePublicToOpenPGP(primary, subkey crypto.PrivateKey, spec *OpenPGPSpec, w io.Writer) error {
+	return encodeOpenPGP(primary, subkey, spec, false, w)
+}
+
+// GetOpenPGPKeys derives the primary key and the encryption subkey for an
+// OpenPGP key. kt is either ED25519 (ed25519 primary key with a cv25519
+// subkey) or one of the RSA key types. The keys are derived for the realm with
+// a separate "openpgp" component, so they are not the same as the keys output
+// by GetKey for any realm and are not shared with other protocols.
+func GetOpenPGPKeys(password, realm string, seed []byte, kt KeyType, allowUnsafe bool) (crypto.PrivateKey, crypto.PrivateKey, error) {
+	subkeyType := kt
+	switch kt {
+	case ED25519:
+		subkeyType = X25519
+	case RSA2048, RSA4096:
+	default:
+		return nil, nil, fmt.Errorf("key type %v is not supported for OpenPGP keys", kt)
+	}
+
+	primary, err := GetKey(password, protocolRealm(realm, "openpgp"), seed, kt, allowUnsafe)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	subkey, err := GetKey(password, protocolRealm(realm, "openpgp-subkey"), seed, subkeyType, allowUnsafe)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return primary, subkey, nil
+}
@@ -0,0 +1,147 @@
+package gokey
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	// hash functions supported by OTPSpec
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+// below code implements HOTP and TOTP one-time passwords as described in RFC
+// 4226 and RFC 6238 and the otpauth:// provisioning URIs understood by most
+// authenticator apps
+
+var otpAlgorithms = map[crypto.Hash]string{
+	crypto.SHA1:   "SHA1",
+	crypto.SHA256: "SHA256",
+	crypto.SHA512: "SHA512",
+}
+
+// OTPSpec describes one-time passwords of a realm
+type OTPSpec struct {
+	// Hash is crypto.SHA1, crypto.SHA256 or crypto.SHA512
+	Hash crypto.Hash
+	// Digits is the number of digits in the code: 6, 7 or 8
+	Digits int
+	// Period is the TOTP time step, usually 30 seconds
+	Period time.Duration
+}
+
+func (spec *OTPSpec) valid() error {
+	if _, ok := otpAlgorithms[spec.Hash]; !ok {
+		return errors.New("unsupported OTP hash function")
+	}
+
+	if spec.Digits < 6 || spec.Digits > 8 {
+		return errors.New("OTP codes should have 6 to 8 digits")
+	}
+
+	if spec.Period < time.Second {
+		return errors.New("invalid TOTP period")
+	}
+
+	return nil
+}
+
+// GetOTPSecret derives the HOTP/TOTP shared secret for the realm. The secret
+// has the size of the hash function output as recommended by RFC 6238.
+func GetOTPSecret(password, realm string, seed []byte, spec *OTPSpec, allowUnsafe bool) ([]byte, error) {
+	err := spec.valid()
+	if err != nil {
+		return nil, err
+	}
+
+	rng, err := getReader(password, realm+"-otp", seed, allowUnsafe)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := make([]byte, spec.Hash.Size())
+	_, err = io.ReadFull(rng, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return secret, nil
+}
+
+// HOTP returns the code for the counter value
+func HOTP(secret []byte, counter uint64, spec *OTPSpec) (string, error) {
+	err := spec.valid()
+	if err != nil {
+		return "", err
+	}
+
+	mac := hmac.New(spec.Hash.New, secret)
+	binary.Write(mac, binary.BigEndian, counter)
+	sum := mac.Sum(nil)
+
+	// dynamic truncation
+	offset := sum[len(sum)-1] & 0xf
+	code := binary.BigEndian.Uint32(sum[offset:]) & 0x7fffffff
+
+	modulo := uint32(1)
+	for i := 0; i < spec.Digits; i++ {
+		modulo *= 10
+	}
+
+	return fmt.Sprintf("%0*d", spec.Digits, code%modulo), nil
+}
+
+// TOTP returns the code for the time
+func TOTP(secret []byte, t time.Time, spec *OTPSpec) (string, error) {
+	err := spec.valid()
+	if err != nil {
+		return "", err
+	}
+
+	return HOTP(secret, uint64(t.Unix())/uint64(spec.Period/time.Second), spec)
+}
+
+// OTPAuthURI returns the otpauth:// provisioning URI of the secret. HOTP URI
+// is returned, if counter is not nil.
+func OTPAuthURI(secret []byte, issuer, account string, counter *uint64, spec *OTPSpec) (string, error) {
+	err := spec.valid()
+	if err != nil {
+		return "", err
+	}
+
+	if account == "" || strings.Contains(issuer, ":") || strings.Contains(account, ":") {
+		return "", errors.New("invalid OTP issuer or account name")
+	}
+
+	label := account
+	if issuer != "" {
+		label = issuer + ":" + account
+	}
+
+	query := url.Values{}
+	query.Set("secret", strings.TrimRight(base32.StdEncoding.EncodeToString(secret), "="))
+	if issuer != "" {
+		query.Set("issuer", issuer)
+	}
+	query.Set("algorithm", otpAlgorithms[spec.Hash])
+	query.Set("digits", fmt.Sprint(spec.Digits))
+
+	otpType := "totp"
+	if counter != nil {
+		otpType = "hotp"
+		query.Set("counter", fmt.Sprint(*counter))
+	} else {
+		query.Set("period", fmt.Sprint(int64(spec.Period/time.Second)))
+	}
+
+	uri := url.URL{Scheme: "otpauth", Host: otpType, Path: "/" + label, RawQuery: query.Encode()}
+	return uri.String(), nil
+}
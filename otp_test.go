@@ -0,0 +1,95 @@
+package gokey
+
+import (
+	"crypto"
+	"testing"
+	"time"
+)
+
+func TestHOTP(t *testing.T) {
+	// RFC 4226 appendix D
+	spec := &OTPSpec{Hash: crypto.SHA1, Digits: 6, Period: 30 * time.Second}
+	for counter, expected := range []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"} {
+		code, err := HOTP([]byte("12345678901234567890"), uint64(counter), spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if code != expected {
+			t.Fatalf("invalid HOTP code %v for counter %v", code, counter)
+		}
+	}
+}
+
+func TestTOTP(t *testing.T) {
+	// RFC 6238 appendix B
+	secrets := map[crypto.Hash]string{
+		crypto.SHA1:   "12345678901234567890",
+		crypto.SHA256: "12345678901234567890123456789012",
+		crypto.SHA512: "1234567890123456789012345678901234567890123456789012345678901234",
+	}
+
+	for _, test := range []struct {
+		time  int64
+		codes map[crypto.Hash]string
+	}{
+		{59, map[crypto.Hash]string{crypto.SHA1: "94287082", crypto.SHA256: "46119246", crypto.SHA512: "90693936"}},
+		{1111111109, map[crypto.Hash]string{crypto.SHA1: "07081804", crypto.SHA256: "68084774", crypto.SHA512: "25091201"}},
+		{20000000000, map[crypto.Hash]string{crypto.SHA1: "65353130", crypto.SHA256: "77737706", crypto.SHA512: "47863826"}},
+	} {
+		for hash, expected := range test.codes {
+			code, err := TOTP([]byte(secrets[hash]), time.Unix(test.time, 0), &OTPSpec{Hash: hash, Digits: 8, Period: 30 * time.Second})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if code != expected {
+				t.Fatalf("invalid TOTP code %v for time %v", code, test.time)
+			}
+		}
+	}
+}
+
+func TestOTPAuthURI(t *testing.T) {
+	spec := &OTPSpec{Hash: crypto.SHA1, Digits: 6, Period: 30 * time.Second}
+	uri, err := OTPAuthURI([]byte("12345678901234567890"), "Example Co", "alice@example.com", nil, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if uri != "otpauth://totp/Example%20Co:alice@example.com?algorithm=SHA1&digits=6&issuer=Example+Co&period=30&secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ" {
+		t.Fatalf("invalid TOTP URI %v", uri)
+	}
+
+	counter := uint64(5)
+	uri, err = OTPAuthURI([]byte("12345678901234567890"), "", "alice", &counter, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if uri != "otpauth://hotp/alice?algorithm=SHA1&counter=5&digits=6&secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ" {
+		t.Fatalf("invalid HOTP URI %v", uri)
+	}
+}
+
+func TestGetOTPSecret(t *testing.T) {
+	spec := &OTPSpec{Hash: crypto.SHA256, Digits: 6, Period: 30 * time.Second}
+	secret, err := GetOTPSecret("pass1", "example.com", nil, spec, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(secret) != 32 {
+		t.Fatal("invalid OTP secret size")
+	}
+
+	_, err = GetOTPSecret("pass1", "example.com", nil, spec, false)
+	if err == nil {
+		t.Fatal("allowed unsafe OTP secret generation")
+	}
+
+	_, err = GetOTPSecret("pass1", "example.com", nil, &OTPSpec{Hash: crypto.MD5, Digits: 6, Period: 30 * time.Second}, true)
+	if err == nil {
+		t.Fatal("allowed unsupported OTP hash function")
+	}
+}
@@ -0,0 +1,143 @@
+package gokey
+
+import (
+	"bufio"
+	_ "embed"
+	"errors"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// EFF large wordlist from https://www.eff.org/deeplinks/2016/07/new-wordlists-random-passphrases
+//
+//go:embed wordlists/eff_large_wordlist.txt
+var effLargeWordlist string
+
+var effLargeWords = mustParseWordlist(effLargeWordlist)
+
+const passphraseSymbols = "~!@#$%^&*()-_=+[]{};:,.<>/?"
+
+// PassphraseSpec describes a diceware-style passphrase
+type PassphraseSpec struct {
+	Words     int
+	Separator string
+	// Capitalize makes the first letter of every word upper case
+	Capitalize bool
+	// Digit and Symbol append a random digit or symbol to a random word
+	Digit  bool
+	Symbol bool
+	// Wordlist is the list of words to choose from, the EFF large wordlist
+	// is used if it is empty
+	Wordlist []string
+}
+
+// ParseWordlist reads a wordlist with one word per line. Lines can be
+// prefixed with dice rolls as in diceware wordlists. Duplicate words are
+// removed, so every word has the same probability.
+func ParseWordlist(r io.Reader) ([]string, error) {
+	var words []string
+	seen := make(map[string]bool)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		word := fields[len(fields)-1]
+		if !seen[word] {
+			seen[word] = true
+			words = append(words, word)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(words) < 2 {
+		return nil, errors.New("wordlist should have at least two different words")
+	}
+
+	return words, nil
+}
+
+func mustParseWordlist(list string) []string {
+	words, err := ParseWordlist(strings.NewReader(list))
+	if err != nil {
+		panic(err)
+	}
+
+	return words
+}
+
+func (spec *PassphraseSpec) wordlist() []string {
+	if len(spec.Wordlist) == 0 {
+		return effLargeWords
+	}
+
+	return spec.Wordlist
+}
+
+func capitalize(word string) string {
+	r, size := utf8.DecodeRuneInString(word)
+	return string(unicode.ToUpper(r)) + word[size:]
+}
+
+func (keygen *KeyGen) GeneratePassphrase(spec *PassphraseSpec) (string, error) {
+	if spec.Words <= 0 {
+		return "", errors.New("invalid passphrase specification")
+	}
+
+	wordlist := spec.wordlist()
+	words := make([]string, spec.Words)
+	for i := range words {
+		pos, err := randIndex(keygen.rng, len(wordlist))
+		if err != nil {
+			return "", err
+		}
+
+		words[i] = wordlist[pos]
+		if spec.Capitalize {
+			words[i] = capitalize(words[i])
+		}
+	}
+
+	for _, insert := range []struct {
+		enabled bool
+		chars   string
+	}{
+		{spec.Digit, "0123456789"},
+		{spec.Symbol, passphraseSymbols},
+	} {
+		if !insert.enabled {
+			continue
+		}
+
+		word, err := randIndex(keygen.rng, len(words))
+		if err != nil {
+			return "", err
+		}
+
+		pos, err := randIndex(keygen.rng, len(insert.chars))
+		if err != nil {
+			return "", err
+		}
+
+		words[word] += insert.chars[pos : pos+1]
+	}
+
+	return strings.Join(words, spec.Separator), nil
+}
+
+func GetPassphrase(password, realm string, seed []byte, spec *PassphraseSpec) (string, error) {
+	rng, err := getReader(password, realm+"-passphrase", seed, true)
+	if err != nil {
+		return "", err
+	}
+
+	gen := &KeyGen{rng}
+	return gen.GeneratePassphrase(spec)
+}
@@ -0,0 +1,110 @@
+package gokey
+
+import (
+	"bytes"
+	"crypto/rand"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+func TestEFFLargeWordlist(t *testing.T) {
+	if len(effLargeWords) != 7776 || effLargeWords[0] != "abacus" || effLargeWords[7775] != "zoom" {
+		t.Fatal("invalid EFF large wordlist")
+	}
+}
+
+func TestRandIndex(t *testing.T) {
+	// 0xffffffff is always rejected and 0xfffffffe is out of the last full bucket for 7776
+	rng := bytes.NewReader([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xfe, 0xff, 0xff, 0xff, 0xfd, 0x00, 0x00, 0x00, 0x00})
+	index, err := randIndex(rng, 7776)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if index != 0 {
+		t.Fatal("biased value was not rejected")
+	}
+
+	for i := 0; i < 1000; i++ {
+		index, err = randIndex(rand.Reader, 7776)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if index < 0 || index >= 7776 {
+			t.Fatalf("index %v out of range", index)
+		}
+	}
+}
+
+func TestParseWordlist(t *testing.T) {
+	words, err := ParseWordlist(strings.NewReader("11 alpha\n12 beta\n\n13 alpha\ngamma\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(words, " ") != "alpha beta gamma" {
+		t.Fatalf("invalid wordlist %v", words)
+	}
+
+	_, err = ParseWordlist(strings.NewReader("alpha\nalpha\n"))
+	if err == nil {
+		t.Fatal("allowed wordlist with one word")
+	}
+}
+
+func TestGeneratePassphrase(t *testing.T) {
+	keygen := &KeyGen{rand.Reader}
+	// some EFF words contain hyphens, so space is used as the separator
+	spec := &PassphraseSpec{Words: 6, Separator: " ", Capitalize: true, Digit: true, Symbol: true}
+
+	passphrase, err := keygen.GeneratePassphrase(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	words := strings.Split(passphrase, " ")
+	if len(words) != 6 {
+		t.Fatalf("invalid number of words in %v", passphrase)
+	}
+
+	if !strings.ContainsAny(passphrase, "0123456789") {
+		t.Fatalf("no digit in %v", passphrase)
+	}
+
+	for _, word := range words {
+		if !unicode.IsUpper([]rune(word)[0]) {
+			t.Fatalf("word %v is not capitalized", word)
+		}
+	}
+
+	_, err = keygen.GeneratePassphrase(&PassphraseSpec{})
+	if err == nil {
+		t.Fatal("allowed passphrase without words")
+	}
+}
+
+func TestGetPassphrase(t *testing.T) {
+	spec := &PassphraseSpec{Words: 5, Separator: " ", Wordlist: []string{"alpha", "beta", "gamma"}}
+
+	passphrase1, err := GetPassphrase("pass1", "example.com", nil, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	passphrase2, err := GetPassphrase("pass1", "example.com", nil, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if passphrase1 != passphrase2 {
+		t.Fatal("passphrases with same invocation options do not match")
+	}
+
+	for _, word := range strings.Fields(passphrase1) {
+		if word != "alpha" && word != "beta" && word != "gamma" {
+			t.Fatalf("word %v is not from the wordlist", word)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package gokey
+
+import (
+	"errors"
+	"strconv"
+)
+
+// below code generates numeric PINs avoiding the patterns people tend to
+// choose, which are the first ones tried by an attacker: repeated digits,
+// ascending or descending runs, dates and well-known PINs
+
+// PIN lengths supported by GeneratePIN
+const (
+	MinPINLength = 4
+	MaxPINLength = 16
+)
+
+// common PINs, which are not caught by the pattern rules, for example
+// keypad shapes
+var commonPINs = map[string]bool{
+	"2580": true, "0852": true, "1470": true, "0741": true, "3690": true,
+	"0963": true, "1379": true, "1397": true, "1357": true, "2468": true,
+	"5683": true, "1590": true, "7531": true, "8642": true, "1478": true,
+	"159753": true, "147258": true, "258369": true, "369258": true,
+	"789456": true, "456123": true, "123654": true, "951753": true,
+	"112233": true, "102030": true, "741852": true, "147852": true,
+	"246810": true, "135790": true, "142536": true,
+	"11223344": true, "14725836": true, "25802580": true,
+}
+
+// pinRepeated reports, if the PIN is a repetition of a shorter block (1111,
+// 1212, 123123) or contains a digit three times in a row
+func pinRepeated(pin string) bool {
+	for i := 2; i < len(pin); i++ {
+		if pin[i] == pin[i-1] && pin[i] == pin[i-2] {
+			return true
+		}
+	}
+
+	for size := 1; size <= len(pin)/2; size++ {
+		if len(pin)%size != 0 {
+			continue
+		}
+
+		block := true
+		for i := size; i < len(pin); i++ {
+			if pin[i] != pin[i-size] {
+				block = false
+				break
+			}
+		}
+		if block {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pinRun reports, if the PIN contains 4 ascending or descending digits in a row
+// (9 is followed by 0, so 7890 is a run as well)
+func pinRun(pin string) bool {
+	for _, step := range []int{1, 9} {
+		length := 1
+		for i := 1; i < len(pin); i++ {
+			if (int(pin[i]-'0')-int(pin[i-1]-'0')+10)%10 == step {
+				length++
+			} else {
+				length = 1
+			}
+
+			if length >= 4 {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func validDate(day, month int) bool {
+	days := []int{31, 29, 31, 30, 31, 30, 31, 31, 30, 31, 30, 31}
+	return month >= 1 && month <= 12 && day >= 1 && day <= days[month-1]
+}
+
+// pinDate reports, if the PIN can be read as a date: DDMM, MMDD or a year from
+// 1900 to 2099 for 4 digits, DDMMYY, MMDDYY or YYMMDD for 6 digits and
+// DDMMYYYY, MMDDYYYY or YYYYMMDD for 8 digits
+func pinDate(pin string) bool {
+	n := func(from, to int) int {
+		v, _ := strconv.Atoi(pin[from:to])
+		return v
+	}
+
+	switch len(pin) {
+	case 4:
+		return validDate(n(0, 2), n(2, 4)) || validDate(n(2, 4), n(0, 2)) || (n(0, 2) == 19 || n(0, 2) == 20)
+	case 6:
+		return validDate(n(0, 2), n(2, 4)) || validDate(n(2, 4), n(0, 2)) || validDate(n(4, 6), n(2, 4))
+	case 8:
+		year := func(y int) bool { return y >= 1900 && y <= 2099 }
+		return (year(n(4, 8)) && (validDate(n(0, 2), n(2, 4)) || validDate(n(2, 4), n(0, 2)))) ||
+			(year(n(0, 4)) && validDate(n(6, 8), n(4, 6)))
+	}
+
+	return false
+}
+
+// WeakPIN reports, if the PIN follows a pattern people tend to choose
+func WeakPIN(pin string) bool {
+	return commonPINs[pin] || pinRepeated(pin) || pinRun(pin) || pinDate(pin)
+}
+
+// GeneratePIN returns a PIN of the given number of digits, which is not weak
+// according to WeakPIN
+func (keygen *KeyGen) GeneratePIN(length int) (string, error) {
+	if length < MinPINLength || length > MaxPINLength {
+		return "", errors.New("invalid PIN length")
+	}
+
+	pin := make([]byte, length)
+	for {
+		for i := range pin {
+			digit, err := randIndex(keygen.rng, 10)
+			if err != nil {
+				return "", err
+			}
+			pin[i] = byte('0' + digit)
+		}
+
+		if !WeakPIN(string(pin)) {
+			return string(pin), nil
+		}
+	}
+}
+
+// GetPIN returns the PIN for the realm
+func GetPIN(password, realm string, seed []byte, length int) (string, error) {
+	rng, err := getReader(password, realm+"-pin", seed, true)
+	if err != nil {
+		return "", err
+	}
+
+	gen := &KeyGen{rng}
+	return gen.GeneratePIN(length)
+}
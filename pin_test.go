@@ -0,0 +1,61 @@
+package gokey
+
+import (
+	"crypto/rand"
+	"testing"
+)
+
+func TestWeakPIN(t *testing.T) {
+	for _, pin := range []string{
+		"0000", "1212", "1234", "4321", "7890", "1004", "2580", "1990", "2024", "3112", "1231",
+		"123123", "654321", "112233", "000123", "241299", "990524",
+		"12345678", "24121999", "20240101", "0815",
+	} {
+		if !WeakPIN(pin) {
+			t.Fatalf("PIN %v is not weak", pin)
+		}
+	}
+
+	for _, pin := range []string{"3869", "5082", "738264", "94736251"} {
+		if WeakPIN(pin) {
+			t.Fatalf("PIN %v is weak", pin)
+		}
+	}
+}
+
+func TestGeneratePIN(t *testing.T) {
+	keygen := &KeyGen{rand.Reader}
+	for length := MinPINLength; length <= MaxPINLength; length++ {
+		pin, err := keygen.GeneratePIN(length)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(pin) != length || WeakPIN(pin) {
+			t.Fatalf("invalid PIN %v", pin)
+		}
+	}
+
+	for _, length := range []int{0, MinPINLength - 1, MaxPINLength + 1} {
+		_, err := keygen.GeneratePIN(length)
+		if err == nil {
+			t.Fatalf("generated PIN of %v digits", length)
+		}
+	}
+}
+
+func TestGetPIN(t *testing.T) {
+	pin1, err := GetPIN("pass1", "front-door", nil, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pin2, err := GetPIN("pass1", "front-door", nil, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pin1 != pin2 {
+		t.Fatal("PINs with same invocation options do not match")
+	}
+}
@@ -0,0 +1,180 @@
+package gokey
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// pronounceable passwords are built from consonant-vowel syllables, so they can
+// be read aloud. Consonants and vowels alternate, so no password can be built
+// from different choices and the entropy estimate is exact.
+const (
+	consonants = "bdfghjklmnprstvz"
+	vowels     = "aeiou"
+)
+
+// pronounceableTables returns the consonants and vowels, which are allowed in
+// both cases needed by the spec, and the digits and special characters of the
+// alphabet
+func (spec *PasswordSpec) pronounceableTables(upper int) ([]rune, []rune, []rune, []rune) {
+	allowed := make(map[rune]bool)
+	var digits, special []rune
+	for _, c := range spec.alphabet() {
+		allowed[c] = true
+		switch {
+		case unicode.IsDigit(c):
+			digits = append(digits, c)
+		case unicode.IsSymbol(c) || unicode.IsPunct(c):
+			if spec.AllowedSpecial == "" || strings.ContainsRune(spec.AllowedSpecial, c) {
+				special = append(special, c)
+			}
+		}
+	}
+
+	// the case of a letter is chosen after the letter, so it must be allowed
+	// in every case it may get
+	letters := func(table string) []rune {
+		var filtered []rune
+		for _, c := range table {
+			if (spec.Lower == 0 || allowed[c]) && (upper == 0 || allowed[unicode.ToUpper(c)]) {
+				filtered = append(filtered, c)
+			}
+		}
+		return filtered
+	}
+
+	return letters(consonants), letters(vowels), digits, special
+}
+
+// GeneratePronounceable returns a pronounceable password with the digits and
+// special characters required by the spec at random positions along with the
+// estimated entropy of the password in bits. Exactly spec.Upper letters are
+// upper case, all letters are upper case, if spec.Lower is 0. Only the
+// characters of spec.Alphabet are used and ambiguous characters are excluded,
+// if spec.ExcludeAmbiguous is set.
+func (keygen *KeyGen) GeneratePronounceable(spec *PasswordSpec) (string, float64, error) {
+	err := spec.Validate()
+	if err != nil {
+		return "", 0, err
+	}
+
+	letters := spec.Length - spec.Digits - spec.Special
+	if letters < 2 || spec.Lower+spec.Upper == 0 {
+		return "", 0, errors.New("pronounceable password needs at least two letters")
+	}
+
+	upper := spec.Upper
+	if spec.Lower == 0 {
+		upper = letters
+	}
+
+	consonantTable, vowelTable, digitTable, specialTable := spec.pronounceableTables(upper)
+	if len(consonantTable) == 0 || len(vowelTable) == 0 {
+		return "", 0, errors.New("no pronounceable consonants or vowels in the alphabet")
+	}
+
+	password := make([]rune, 0, spec.Length)
+	var entropy float64
+	for i := 0; i < letters; i++ {
+		table := consonantTable
+		if i%2 == 1 {
+			table = vowelTable
+		}
+
+		c, err := keygen.randRune(table)
+		if err != nil {
+			return "", 0, err
+		}
+		password = append(password, c)
+		entropy += math.Log2(float64(len(table)))
+	}
+
+	// upper case letters are chosen among the letter positions
+	positions, err := keygen.choosePositions(letters, upper)
+	if err != nil {
+		return "", 0, err
+	}
+	for _, pos := range positions {
+		password[pos] = unicode.ToUpper(password[pos])
+	}
+	entropy += log2Binomial(letters, upper)
+
+	// the first chosen positions get the digits and the rest get the special
+	// characters, the letters keep their order in the remaining positions
+	extra := spec.Digits + spec.Special
+	positions, err = keygen.choosePositions(spec.Length, extra)
+	if err != nil {
+		return "", 0, err
+	}
+	entropy += log2Binomial(spec.Length, extra) + log2Binomial(extra, spec.Digits)
+
+	result := make([]rune, spec.Length)
+	isExtra := make([]bool, spec.Length)
+	for i, pos := range positions {
+		table := digitTable
+		if i >= spec.Digits {
+			table = specialTable
+		}
+
+		c, err := keygen.randRune(table)
+		if err != nil {
+			return "", 0, err
+		}
+		result[pos] = c
+		isExtra[pos] = true
+		entropy += math.Log2(float64(len(table)))
+	}
+
+	next := 0
+	for i := range result {
+		if !isExtra[i] {
+			result[i] = password[next]
+			next++
+		}
+	}
+
+	return string(result), entropy, nil
+}
+
+// choosePositions returns count of n positions chosen with partial Fisher-Yates
+// shuffle in the order they were chosen
+func (keygen *KeyGen) choosePositions(n, count int) ([]int, error) {
+	positions := make([]int, n)
+	for i := range positions {
+		positions[i] = i
+	}
+
+	for i := 0; i < count; i++ {
+		j, err := randIndex(keygen.rng, n-i)
+		if err != nil {
+			return nil, err
+		}
+		positions[i], positions[i+j] = positions[i+j], positions[i]
+	}
+
+	return positions[:count], nil
+}
+
+// log2Binomial returns the base 2 logarithm of "n choose k"
+func log2Binomial(n, k int) float64 {
+	var result float64
+	for i := 0; i < k; i++ {
+		result += math.Log2(float64(n-i)) - math.Log2(float64(i+1))
+	}
+
+	return result
+}
+
+// GetPronounceable returns the pronounceable password for the realm along with
+// its estimated entropy in bits
+func GetPronounceable(password, realm string, seed []byte, spec *PasswordSpec) (string, float64, error) {
+	rng, err := getReader(password, realm+"-pronounceable", seed, true)
+	if err != nil {
+		return "", 0, err
+	}
+
+	gen := &KeyGen{rng}
+	return gen.GeneratePronounceable(spec)
+}
@@ -0,0 +1,109 @@
+package gokey
+
+import (
+	"crypto/rand"
+	"math"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+func TestGeneratePronounceable(t *testing.T) {
+	keygen := &KeyGen{rand.Reader}
+
+	for _, spec := range []*PasswordSpec{
+		{Length: 16, Upper: 3, Lower: 3, Digits: 1, Special: 1},
+		{Length: 12, Upper: 0, Lower: 1, Digits: 2, Special: 0},
+		{Length: 9, Upper: 1, Lower: 0, Digits: 0, Special: 1, AllowedSpecial: "-"},
+		{Length: 16, Upper: 2, Lower: 2, Digits: 2, Special: 2, ExcludeAmbiguous: true},
+		{Length: 10, Upper: 0, Lower: 1, Digits: 2, Special: 0, Alphabet: "bdkmaeu23"},
+	} {
+		for i := 0; i < 100; i++ {
+			password, _, err := keygen.GeneratePronounceable(spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(password) != spec.Length || !spec.Compliant(password) {
+				t.Fatalf("password %v does not match the spec %+v", password, spec)
+			}
+
+			alphabet := string(spec.alphabet())
+			var letters []rune
+			for _, c := range password {
+				if !strings.ContainsRune(alphabet, c) {
+					t.Fatalf("password %v has characters not in the alphabet %v", password, alphabet)
+				}
+				if unicode.IsLetter(c) {
+					letters = append(letters, unicode.ToLower(c))
+				}
+			}
+
+			for j, c := range letters {
+				if j%2 == 0 && !strings.ContainsRune(consonants, c) || j%2 == 1 && !strings.ContainsRune(vowels, c) {
+					t.Fatalf("password %v is not pronounceable", password)
+				}
+			}
+		}
+	}
+
+	_, _, err := keygen.GeneratePronounceable(&PasswordSpec{Length: 3, Upper: 0, Lower: 1, Digits: 2})
+	if err == nil {
+		t.Fatal("generated pronounceable password with one letter")
+	}
+
+	_, _, err = keygen.GeneratePronounceable(&PasswordSpec{Length: 8, Upper: 0, Lower: 1, Alphabet: "bdfg"})
+	if err == nil {
+		t.Fatal("generated pronounceable password without vowels")
+	}
+
+	// digits and special characters are not always at the end
+	suffix := 0
+	spec := &PasswordSpec{Length: 8, Upper: 0, Lower: 1, Digits: 1, Special: 0}
+	for i := 0; i < 100; i++ {
+		password, _, err := keygen.GeneratePronounceable(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if unicode.IsDigit(rune(password[len(password)-1])) {
+			suffix++
+		}
+	}
+	if suffix == 100 {
+		t.Fatal("digits are always at the end of the password")
+	}
+}
+
+func TestPronounceableEntropy(t *testing.T) {
+	spec := &PasswordSpec{Length: 8, Upper: 2, Lower: 1, Digits: 1, Special: 1, AllowedSpecial: "!@#$"}
+	_, entropy, err := (&KeyGen{rand.Reader}).GeneratePronounceable(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 6 letters, 2 of them upper case, 1 digit and 1 of 4 special characters
+	// at 2 of 8 positions in either order
+	expected := 3*math.Log2(16) + 3*math.Log2(5) + math.Log2(15) + math.Log2(28) + 1 + math.Log2(10) + math.Log2(4)
+	if math.Abs(entropy-expected) > 1e-9 {
+		t.Fatalf("entropy %v, expected %v", entropy, expected)
+	}
+}
+
+func TestGetPronounceable(t *testing.T) {
+	spec := &PasswordSpec{Length: 16, Upper: 3, Lower: 3, Digits: 1, Special: 1}
+
+	pass1, _, err := GetPronounceable("pass1", "example.com", nil, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pass2, _, err := GetPronounceable("pass1", "example.com", nil, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pass1 != pass2 {
+		t.Fatal("passwords with same invocation options do not match")
+	}
+}
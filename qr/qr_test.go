@@ -0,0 +1,91 @@
+package qr
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestReedSolomon(t *testing.T) {
+	// "HELLO WORLD" as 1-M QR code from https://www.thonky.com/qr-code-tutorial/error-correction-coding
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	ecc := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+
+	if !bytes.Equal(reedSolomonRemainder(data, reedSolomonDivisor(len(ecc))), ecc) {
+		t.Fatal("invalid error correction codewords")
+	}
+}
+
+func TestFormatBits(t *testing.T) {
+	for _, test := range []struct {
+		level Level
+		mask  int
+		bits  int
+	}{
+		{L, 0, 0x77c4},
+		{M, 0, 0x5412},
+		{Q, 7, 0x2bed},
+		{H, 3, 0x19d0},
+	} {
+		if bits := formatBits(test.level, test.mask); bits != test.bits {
+			t.Fatalf("invalid format bits %015b for level %v and mask %v", bits, test.level, test.mask)
+		}
+	}
+}
+
+func TestEncodeVersion(t *testing.T) {
+	for _, test := range []struct {
+		length  int
+		level   Level
+		version int
+	}{
+		{17, L, 1},
+		{18, L, 2},
+		{7, H, 1},
+		{8, H, 2},
+		{2953, L, 40},
+		{1273, H, 40},
+	} {
+		c, err := Encode(make([]byte, test.length), test.level)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if c.Version != test.version || c.Size != test.version*4+17 {
+			t.Fatalf("invalid version %v for %v bytes", c.Version, test.length)
+		}
+	}
+
+	_, err := Encode(make([]byte, 2954), L)
+	if err == nil {
+		t.Fatal("allowed data too long for a QR code")
+	}
+
+	_, err = Encode([]byte("data"), Level(4))
+	if err == nil {
+		t.Fatal("allowed invalid error correction level")
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	c, err := Encode([]byte("otpauth://totp/example?secret=GEZDGNBVGY3TQOJQ"), M)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var b strings.Builder
+	err = c.WriteText(&b, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	if len(lines) != (c.Size+2*QuietZone+1)/2 {
+		t.Fatalf("invalid number of lines %v", len(lines))
+	}
+
+	// top left corner of the finder pattern is dark, so it is not drawn
+	if !strings.HasPrefix(lines[QuietZone/2], "████ ") {
+		t.Fatal("invalid finder pattern")
+	}
+}
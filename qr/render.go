@@ -0,0 +1,88 @@
+package qr
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+)
+
+// QuietZone is the width of the light border around the code in modules
+const QuietZone = 4
+
+// WriteText renders the code with Unicode half block characters, so every line
+// of text holds two rows of modules. Light modules are drawn with the block
+// characters, which suits terminals with dark background. Use invert for
+// terminals with light background.
+func (c *Code) WriteText(w io.Writer, invert bool) error {
+	bw := bufio.NewWriter(w)
+	for y := -QuietZone; y < c.Size+QuietZone; y += 2 {
+		for x := -QuietZone; x < c.Size+QuietZone; x++ {
+			top := c.Dark(x, y) == invert
+			bottom := c.Dark(x, y+1) == invert
+			if y+1 >= c.Size+QuietZone {
+				bottom = false
+			}
+
+			switch {
+			case top && bottom:
+				bw.WriteString("█")
+			case top:
+				bw.WriteString("▀")
+			case bottom:
+				bw.WriteString("▄")
+			default:
+				bw.WriteString(" ")
+			}
+		}
+		bw.WriteString("\n")
+	}
+
+	return bw.Flush()
+}
+
+// Image returns the code as a grayscale image with scale pixels per module
+func (c *Code) Image(scale int) image.Image {
+	size := (c.Size + 2*QuietZone) * scale
+	img := image.NewGray(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if c.Dark(x/scale-QuietZone, y/scale-QuietZone) {
+				img.SetGray(x, y, color.Gray{Y: 0})
+			} else {
+				img.SetGray(x, y, color.Gray{Y: 0xff})
+			}
+		}
+	}
+
+	return img
+}
+
+// WritePNG writes the code as a PNG image with scale pixels per module
+func (c *Code) WritePNG(w io.Writer, scale int) error {
+	return png.Encode(w, c.Image(scale))
+}
+
+// WriteSVG writes the code as an SVG image, where every module is a unit square
+func (c *Code) WriteSVG(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	size := c.Size + 2*QuietZone
+
+	fmt.Fprintf(bw, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	fmt.Fprintf(bw, "<svg xmlns=\"http://www.w3.org/2000/svg\" version=\"1.1\" viewBox=\"0 0 %d %d\" stroke=\"none\">\n", size, size)
+	fmt.Fprintf(bw, "\t<rect width=\"100%%\" height=\"100%%\" fill=\"#ffffff\"/>\n")
+	fmt.Fprintf(bw, "\t<path d=\"")
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.Dark(x, y) {
+				fmt.Fprintf(bw, "M%d,%dh1v1h-1z", x+QuietZone, y+QuietZone)
+			}
+		}
+	}
+	fmt.Fprintf(bw, "\" fill=\"#000000\"/>\n")
+	fmt.Fprintf(bw, "</svg>\n")
+
+	return bw.Flush()
+}
@@ -0,0 +1,52 @@
+package gokey
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/idna"
+	"golang.org/x/net/publicsuffix"
+)
+
+// NormalizeRealm reduces a URL or a host name to the registrable domain, so
+// "https://www.Example.com/login", "example.com" and "EXAMPLE.COM" produce the
+// same passwords. The scheme, the user info, the port, the path and the query
+// are stripped and the host name is lower cased and converted to the ASCII
+// form of internationalized domain names. The registrable domain is the public
+// suffix ("com", "co.uk" and so on according to the public suffix list embedded
+// into the binary) together with one more label. IP addresses and host names,
+// which are public suffixes themselves, are only lower cased.
+func NormalizeRealm(realm string) (string, error) {
+	realm = strings.TrimSpace(realm)
+	if !strings.Contains(realm, "://") {
+		realm = "//" + realm
+	}
+
+	u, err := url.Parse(realm)
+	if err != nil {
+		return "", errors.New("realm is not a URL or a host name")
+	}
+
+	host := strings.TrimSuffix(u.Hostname(), ".")
+	if host == "" {
+		return "", errors.New("realm is not a URL or a host name")
+	}
+
+	if net.ParseIP(host) != nil {
+		return strings.ToLower(host), nil
+	}
+
+	host, err = idna.Lookup.ToASCII(host)
+	if err != nil {
+		return "", errors.New("realm is not a URL or a host name")
+	}
+
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return host, nil
+	}
+
+	return domain, nil
+}
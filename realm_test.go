@@ -0,0 +1,34 @@
+package gokey
+
+import "testing"
+
+func TestNormalizeRealm(t *testing.T) {
+	for realm, expected := range map[string]string{
+		"https://www.Example.com/login":           "example.com",
+		"example.com":                             "example.com",
+		"EXAMPLE.COM":                             "example.com",
+		"http://user@shop.example.co.uk:8080/a?b": "example.co.uk",
+		"www.example.com.":                        "example.com",
+		"192.168.1.1:80":                          "192.168.1.1",
+		"https://[2001:DB8::1]/":                  "2001:db8::1",
+		"Bücher.de":                               "xn--bcher-kva.de",
+		"co.uk":                                   "co.uk",
+		"localhost":                               "localhost",
+	} {
+		normalized, err := NormalizeRealm(realm)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if normalized != expected {
+			t.Fatalf("realm %v normalized to %v, expected %v", realm, normalized, expected)
+		}
+	}
+
+	for _, realm := range []string{"", "http://", "my server key"} {
+		_, err := NormalizeRealm(realm)
+		if err == nil {
+			t.Fatalf("normalized invalid realm %q", realm)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package gokey
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+)
+
+// below code generates passwords matching a regular expression, which some
+// systems publish as their password rule. Only regular expressions describing
+// a finite set of strings are supported: literals, character classes, bounded
+// repetition, alternation, groups and anchors. Every choice (an alternative, a
+// repetition count and a character from a class) is made uniformly from the
+// DRNG.
+
+// character classes (and the "." class) are limited to printable ASCII
+// characters, so negated classes like [^a-z] do not produce arbitrary Unicode
+const (
+	regexpMinRune = 0x20
+	regexpMaxRune = 0x7e
+)
+
+var errRegexpUnbounded = errors.New("unbounded repetition is not supported in password patterns, use {n,m}")
+
+// regexpClip limits the character class ranges to printable ASCII characters
+// and returns the number of characters in the class
+func regexpClip(ranges []rune) ([]rune, int) {
+	var clipped []rune
+	size := 0
+	for i := 0; i < len(ranges); i += 2 {
+		lo, hi := ranges[i], ranges[i+1]
+		if lo < regexpMinRune {
+			lo = regexpMinRune
+		}
+		if hi > regexpMaxRune {
+			hi = regexpMaxRune
+		}
+		if lo > hi {
+			continue
+		}
+
+		clipped = append(clipped, lo, hi)
+		size += int(hi-lo) + 1
+	}
+
+	return clipped, size
+}
+
+func (keygen *KeyGen) regexpClass(ranges []rune, b *strings.Builder) error {
+	clipped, size := regexpClip(ranges)
+	if size == 0 {
+		return errors.New("character class in the password pattern has no printable ASCII characters")
+	}
+
+	pos, err := randIndex(keygen.rng, size)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < len(clipped); i += 2 {
+		width := int(clipped[i+1]-clipped[i]) + 1
+		if pos < width {
+			b.WriteRune(clipped[i] + rune(pos))
+			return nil
+		}
+		pos -= width
+	}
+
+	panic("unreachable")
+}
+
+func (keygen *KeyGen) regexpSample(re *syntax.Regexp, b *strings.Builder) error {
+	switch re.Op {
+	case syntax.OpEmptyMatch, syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText:
+		return nil
+	case syntax.OpLiteral:
+		b.WriteString(string(re.Rune))
+		return nil
+	case syntax.OpCharClass:
+		return keygen.regexpClass(re.Rune, b)
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return keygen.regexpClass([]rune{regexpMinRune, regexpMaxRune}, b)
+	case syntax.OpCapture:
+		return keygen.regexpSample(re.Sub[0], b)
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			err := keygen.regexpSample(sub, b)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case syntax.OpAlternate:
+		pos, err := randIndex(keygen.rng, len(re.Sub))
+		if err != nil {
+			return err
+		}
+		return keygen.regexpSample(re.Sub[pos], b)
+	case syntax.OpQuest, syntax.OpRepeat:
+		min, max := 0, 1
+		if re.Op == syntax.OpRepeat {
+			min, max = re.Min, re.Max
+		}
+		if max < 0 {
+			return errRegexpUnbounded
+		}
+
+		count, err := randIndex(keygen.rng, max-min+1)
+		if err != nil {
+			return err
+		}
+
+		for i := 0; i < min+count; i++ {
+			err = keygen.regexpSample(re.Sub[0], b)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case syntax.OpStar, syntax.OpPlus:
+		return errRegexpUnbounded
+	}
+
+	return fmt.Errorf("unsupported construct in the password pattern: %v", re)
+}
+
+// GenerateRegexpPassword returns a password matching the whole pattern, which
+// uses Go regular expression syntax
+func (keygen *KeyGen) GenerateRegexpPassword(pattern string) (string, error) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", fmt.Errorf("invalid password pattern: %v", err)
+	}
+
+	var b strings.Builder
+	err = keygen.regexpSample(re, &b)
+	if err != nil {
+		return "", err
+	}
+
+	// anchors in the middle of the pattern may make it impossible to match
+	matched, err := regexp.MatchString("^(?:"+pattern+")$", b.String())
+	if err != nil {
+		return "", err
+	}
+	if !matched || b.Len() == 0 {
+		return "", errors.New("password pattern can not be satisfied")
+	}
+
+	return b.String(), nil
+}
+
+// GetRegexpPass returns the password for the realm matching the pattern
+func GetRegexpPass(password, realm string, seed []byte, pattern string) (string, error) {
+	rng, err := getReader(password, realm+"-regexp", seed, true)
+	if err != nil {
+		return "", err
+	}
+
+	gen := &KeyGen{rng}
+	return gen.GenerateRegexpPassword(pattern)
+}
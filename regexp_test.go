@@ -0,0 +1,66 @@
+package gokey
+
+import (
+	"crypto/rand"
+	"regexp"
+	"testing"
+)
+
+func TestGenerateRegexpPassword(t *testing.T) {
+	keygen := &KeyGen{rand.Reader}
+
+	for _, pattern := range []string{
+		`^[A-Z][a-z]{6,10}[0-9]{2}[!@#]$`,
+		`(foo|bar|baz)-\d{4}`,
+		`[^a-zA-Z0-9]{8}`,
+		`(?i)pw[[:alnum:]]{12}x?`,
+		`.{16}`,
+		`ключ-[a-f]{8}`,
+	} {
+		re := regexp.MustCompile("^(?:" + pattern + ")$")
+		for i := 0; i < 100; i++ {
+			password, err := keygen.GenerateRegexpPassword(pattern)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !re.MatchString(password) {
+				t.Fatalf("password %q does not match %v", password, pattern)
+			}
+		}
+	}
+
+	for _, pattern := range []string{`[a-z]+`, `a*`, `[a-z]{8,}`, `(`, `a\bb`, `a^b`, `[\x{100}-\x{200}]`, ``} {
+		_, err := keygen.GenerateRegexpPassword(pattern)
+		if err == nil {
+			t.Fatalf("generated password for unsupported pattern %v", pattern)
+		}
+	}
+}
+
+func TestGetRegexpPass(t *testing.T) {
+	pattern := `[a-z]{4}-[0-9]{4}`
+
+	pass1, err := GetRegexpPass("pass1", "example.com", nil, pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pass2, err := GetRegexpPass("pass1", "example.com", nil, pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pass1 != pass2 {
+		t.Fatal("passwords with same invocation options do not match")
+	}
+
+	pass2, err = GetRegexpPass("pass1", "example2.com", nil, pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pass1 == pass2 {
+		t.Fatal("passwords for different realms match")
+	}
+}
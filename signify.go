@@ -0,0 +1,75 @@
+package gokey
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+// below code implements OpenBSD signify key and signature file formats as
+// defined in signify.c from https://github.com/openbsd/src/tree/master/usr.bin/signify
+// secret keys are stored unencrypted (same as "signify -G -n")
+
+// EncodeToSignify writes the unencrypted signify secret key file
+func EncodeToSignify(key *SigningKey, w io.Writer) error {
+	checksum := sha512.Sum512(key.Key)
+
+	// pkalg, kdfalg, kdfrounds (0 - no encryption), salt
+	header := append([]byte("EdBK"), make([]byte, 4+16)...)
+
+	return writeKeyFile(w, "signify secret key", header, checksum[:8], key.ID[:], key.Key)
+}
+
+// EncodePublicToSignify writes the signify public key file
+func EncodePublicToSignify(key *SigningKey, w io.Writer) error {
+	return writeKeyFile(w, "signify public key", []byte("Ed"), key.ID[:], key.public())
+}
+
+// SignifySign writes the signify signature file for the message
+func SignifySign(key *SigningKey, message io.Reader, w io.Writer) error {
+	msg, err := ioutil.ReadAll(message)
+	if err != nil {
+		return err
+	}
+
+	return writeKeyFile(w, "signature from signify secret key", []byte("Ed"), key.ID[:], ed25519.Sign(key.Key, msg))
+}
+
+// SignifyVerify verifies the signify signature of the message
+func SignifyVerify(key *SigningKey, message io.Reader, signature []byte) error {
+	scanner := bufio.NewScanner(bytes.NewReader(signature))
+	if !scanner.Scan() || !strings.HasPrefix(scanner.Text(), "untrusted comment: ") || !scanner.Scan() {
+		return errors.New("invalid signify signature file")
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimRight(scanner.Text(), "\r"))
+	if err != nil {
+		return err
+	}
+
+	if len(sig) != 2+8+ed25519.SignatureSize || string(sig[:2]) != "Ed" {
+		return errors.New("invalid signify signature file")
+	}
+
+	if !bytes.Equal(sig[2:10], key.ID[:]) {
+		return errors.New("signature key ID does not match the key")
+	}
+
+	msg, err := ioutil.ReadAll(message)
+	if err != nil {
+		return err
+	}
+
+	if !ed25519.Verify(key.public(), msg, sig[10:]) {
+		return errors.New("signature verification failed")
+	}
+
+	return nil
+}
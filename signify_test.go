@@ -0,0 +1,51 @@
+package gokey
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"strings"
+	"testing"
+)
+
+func TestSignify(t *testing.T) {
+	key, err := GetSigningKey("pass1", "example.com", nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var secret, public, sig strings.Builder
+	err = EncodeToSignify(key, &secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = EncodePublicToSignify(key, &public)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checksum := sha512.Sum512(key.Key)
+	if data := decodeKeyFile(t, secret.String(), "signify secret key"); len(data) != 104 || !bytes.Equal(data[24:32], checksum[:8]) || !bytes.Equal(data[32:40], key.ID[:]) {
+		t.Fatal("invalid signify secret key")
+	}
+
+	if data := decodeKeyFile(t, public.String(), "signify public key"); len(data) != 42 || !bytes.Equal(data[2:10], key.ID[:]) {
+		t.Fatal("invalid signify public key")
+	}
+
+	message := []byte("release artifact")
+	err = SignifySign(key, bytes.NewReader(message), &sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = SignifyVerify(key, bytes.NewReader(message), []byte(sig.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = SignifyVerify(key, strings.NewReader("tampered artifact"), []byte(sig.String()))
+	if err == nil {
+		t.Fatal("verified signature of a different message")
+	}
+}
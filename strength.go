@@ -0,0 +1,670 @@
+package gokey
+
+import (
+	_ "embed"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// below code estimates the strength of a password as the number of guesses an
+// attacker needs to find it, following zxcvbn (Wheeler, "zxcvbn: Low-Budget
+// Password Strength Estimation", USENIX Security 2016): the password is split
+// into the sequence of patterns (dictionary words, keyboard patterns, repeats,
+// sequences, dates and random characters), which is the easiest to guess
+
+// ranked frequency lists from https://github.com/dropbox/zxcvbn (MIT license)
+var (
+	//go:embed wordlists/zxcvbn/passwords.txt
+	passwordsList string
+	//go:embed wordlists/zxcvbn/english.txt
+	englishList string
+	//go:embed wordlists/zxcvbn/names.txt
+	namesList string
+	//go:embed wordlists/zxcvbn/surnames.txt
+	surnamesList string
+)
+
+// PasswordStrength is the estimated strength of a password
+type PasswordStrength struct {
+	// Guesses is the estimated number of guesses needed to find the password
+	Guesses float64
+	// Score is from 0 (too guessable) to 4 (very unguessable)
+	Score int
+	// Warning describes the weakest pattern found in the password, if the
+	// score is below 3
+	Warning string
+}
+
+// Entropy returns the strength of the password in bits
+func (s *PasswordStrength) Entropy() float64 {
+	return math.Log2(s.Guesses)
+}
+
+type rankedDictionary map[string]int
+
+func newRankedDictionary(words []string) rankedDictionary {
+	d := make(rankedDictionary)
+	for i, word := range words {
+		word = strings.ToLower(word)
+		if _, ok := d[word]; !ok && word != "" {
+			d[word] = i + 1
+		}
+	}
+
+	return d
+}
+
+var (
+	strengthDictionaries map[string]rankedDictionary
+	keyboardGraphs       map[string]keyboardGraph
+	strengthOnce         sync.Once
+)
+
+func initStrength() {
+	strengthDictionaries = map[string]rankedDictionary{
+		"passwords": newRankedDictionary(strings.Fields(passwordsList)),
+		"english":   newRankedDictionary(strings.Fields(englishList)),
+		"names":     newRankedDictionary(strings.Fields(namesList)),
+		"surnames":  newRankedDictionary(strings.Fields(surnamesList)),
+	}
+
+	keyboardGraphs = map[string]keyboardGraph{
+		"qwerty": newKeyboardGraph([]string{"`~ 1! 2@ 3# 4$ 5% 6^ 7& 8* 9( 0) -_ =+", "qQ wW eE rR tT yY uU iI oO pP [{ ]} \\|", "aA sS dD fF gG hH jJ kK lL ;: '\"", "zZ xX cC vV bB nN mM ,< .> /?"}, true),
+		"keypad": newKeyboardGraph([]string{"7 8 9", "4 5 6", "1 2 3", "0"}, false),
+	}
+}
+
+// keyboardGraph maps every character to its key position and the keys to the
+// characters they produce
+type keyboardGraph struct {
+	position map[rune][2]int
+	keys     map[[2]int]string
+	slanted  bool
+	// number of characters and average number of neighbours of a key
+	size   float64
+	degree float64
+}
+
+func newKeyboardGraph(rows []string, slanted bool) keyboardGraph {
+	g := keyboardGraph{position: make(map[rune][2]int), keys: make(map[[2]int]string), slanted: slanted}
+	for y, row := range rows {
+		for x, key := range strings.Fields(row) {
+			// rows of the slanted keyboard below the first one are shifted
+			// half a key to the right
+			if slanted && y > 0 {
+				x++
+			}
+			g.keys[[2]int{x, y}] = key
+			for _, c := range key {
+				g.position[c] = [2]int{x, y}
+			}
+		}
+	}
+
+	neighbours := 0
+	for pos := range g.keys {
+		for _, d := range g.directions() {
+			if _, ok := g.keys[[2]int{pos[0] + d[0], pos[1] + d[1]}]; ok {
+				neighbours++
+			}
+		}
+	}
+	g.size = float64(len(g.position))
+	g.degree = float64(neighbours) / float64(len(g.keys))
+
+	return g
+}
+
+func (g keyboardGraph) directions() [][2]int {
+	if g.slanted {
+		return [][2]int{{-1, 0}, {0, -1}, {1, -1}, {1, 0}, {0, 1}, {-1, 1}}
+	}
+
+	return [][2]int{{-1, -1}, {0, -1}, {1, -1}, {1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}}
+}
+
+// direction returns the direction from the key of a to the key of b or -1, if
+// they are not neighbours
+func (g keyboardGraph) direction(a, b rune) int {
+	pa, ok := g.position[a]
+	if !ok {
+		return -1
+	}
+	pb, ok := g.position[b]
+	if !ok {
+		return -1
+	}
+
+	for i, d := range g.directions() {
+		if pa[0]+d[0] == pb[0] && pa[1]+d[1] == pb[1] {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// shifted reports, if the character is produced with Shift on the keyboard
+func (g keyboardGraph) shifted(c rune) bool {
+	pos, ok := g.position[c]
+	return ok && len([]rune(g.keys[pos])) > 1 && []rune(g.keys[pos])[1] == c
+}
+
+type strengthMatch struct {
+	// i and j are the first and the last character of the match
+	i, j    int
+	pattern string
+	guesses float64
+}
+
+func binomial(n, k int) float64 {
+	if k < 0 || k > n {
+		return 0
+	}
+
+	r := 1.0
+	for i := 1; i <= k; i++ {
+		r = r * float64(n-k+i) / float64(i)
+	}
+	return r
+}
+
+// variations returns the number of ways to choose the positions of the
+// characters of one kind (for example, upper case letters) among the others,
+// considering the most common choices (none, only the first, only the last or
+// all of them) as easy to guess
+func variations(one, other int, obvious bool) float64 {
+	if one == 0 {
+		return 1
+	}
+	if other == 0 || obvious {
+		return 2
+	}
+
+	sum := 0.0
+	for i := 1; i <= one && i <= other; i++ {
+		sum += binomial(one+other, i)
+	}
+	return sum
+}
+
+func uppercaseVariations(word []rune) float64 {
+	var upper, lower int
+	for _, c := range word {
+		if unicode.IsUpper(c) {
+			upper++
+		} else if unicode.IsLower(c) {
+			lower++
+		}
+	}
+
+	first := upper == 1 && unicode.IsUpper(word[0])
+	last := upper == 1 && unicode.IsUpper(word[len(word)-1])
+	return variations(upper, lower, first || last)
+}
+
+// l33t substitutions
+var l33tTable = map[rune][]rune{
+	'4': {'a'}, '@': {'a'}, '8': {'b'}, '(': {'c'}, '{': {'c'}, '[': {'c'}, '<': {'c'},
+	'3': {'e'}, '6': {'g'}, '9': {'g'}, '1': {'i', 'l'}, '!': {'i'}, '|': {'i', 'l'},
+	'7': {'l', 't'}, '0': {'o'}, '$': {'s'}, '5': {'s'}, '+': {'t'}, '%': {'x'}, '2': {'z'},
+}
+
+// unl33t returns the words the l33t word can stand for along with the number of
+// substituted characters
+func unl33t(word []rune) ([]string, int) {
+	variants := []string{""}
+	subs := 0
+	for _, c := range word {
+		letters, ok := l33tTable[c]
+		if !ok {
+			for i := range variants {
+				variants[i] += string(c)
+			}
+			continue
+		}
+
+		subs++
+		var next []string
+		for _, v := range variants {
+			for _, l := range letters {
+				next = append(next, v+string(l))
+			}
+		}
+		if len(next) > 16 {
+			return nil, 0
+		}
+		variants = next
+	}
+
+	return variants, subs
+}
+
+func reversed(word []rune) []rune {
+	r := make([]rune, len(word))
+	for i, c := range word {
+		r[len(word)-1-i] = c
+	}
+	return r
+}
+
+const maxWordLength = 32
+
+func dictionaryMatches(password []rune, dictionaries map[string]rankedDictionary) []strengthMatch {
+	lower := make([]rune, len(password))
+	for i, c := range password {
+		lower[i] = unicode.ToLower(c)
+	}
+
+	// sorted, so the matches are always in the same order
+	var names []string
+	for name := range dictionaries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var matches []strengthMatch
+	for i := range lower {
+		for j := i; j < len(lower) && j-i < maxWordLength; j++ {
+			word := lower[i : j+1]
+			upper := uppercaseVariations(password[i : j+1])
+
+			for _, name := range names {
+				d := dictionaries[name]
+				if rank, ok := d[string(word)]; ok {
+					matches = append(matches, strengthMatch{i, j, name, float64(rank) * upper})
+				}
+
+				if j > i {
+					if rank, ok := d[string(reversed(word))]; ok {
+						matches = append(matches, strengthMatch{i, j, name, float64(rank) * upper * 2})
+					}
+				}
+
+				variants, subs := unl33t(word)
+				if subs == 0 || subs == len(word) {
+					continue
+				}
+				for _, v := range variants {
+					if rank, ok := d[v]; ok {
+						l33t := variations(subs, len(word)-subs, false)
+						matches = append(matches, strengthMatch{i, j, name, float64(rank) * upper * l33t})
+						break
+					}
+				}
+			}
+		}
+	}
+
+	return matches
+}
+
+func spatialMatches(password []rune) []strengthMatch {
+	var matches []strengthMatch
+	for _, g := range keyboardGraphs {
+		for i := 0; i < len(password)-2; {
+			j := i
+			turns := 0
+			last := -1
+			for j+1 < len(password) {
+				d := g.direction(password[j], password[j+1])
+				if d < 0 {
+					break
+				}
+				if d != last {
+					turns++
+					last = d
+				}
+				j++
+			}
+
+			if j-i+1 >= 3 {
+				matches = append(matches, strengthMatch{i, j, "spatial", spatialGuesses(g, password[i:j+1], turns)})
+				i = j
+			} else {
+				i++
+			}
+		}
+	}
+
+	return matches
+}
+
+func spatialGuesses(g keyboardGraph, word []rune, turns int) float64 {
+	guesses := 0.0
+	for i := 2; i <= len(word); i++ {
+		for j := 1; j <= turns && j <= i-1; j++ {
+			guesses += binomial(i-1, j-1) * g.size * math.Pow(g.degree, float64(j))
+		}
+	}
+
+	var shifted int
+	for _, c := range word {
+		if g.shifted(c) {
+			shifted++
+		}
+	}
+	return guesses * variations(shifted, len(word)-shifted, false)
+}
+
+func repeatMatches(password []rune, estimate func([]rune) float64) []strengthMatch {
+	var matches []strengthMatch
+	for i := range password {
+		for size := 1; i+2*size <= len(password); size++ {
+			base := password[i : i+size]
+			count := 1
+			for i+(count+1)*size <= len(password) && string(password[i+count*size:i+(count+1)*size]) == string(base) {
+				count++
+			}
+
+			if count > 1 {
+				matches = append(matches, strengthMatch{i, i + count*size - 1, "repeat", estimate(base) * float64(count)})
+			}
+		}
+	}
+
+	return matches
+}
+
+func sequenceMatches(password []rune) []strengthMatch {
+	var matches []strengthMatch
+	add := func(i, j, delta int) {
+		if j-i < 2 || delta == 0 || delta < -5 || delta > 5 {
+			return
+		}
+
+		first := password[i]
+		base := 26.0
+		switch {
+		case strings.ContainsRune("aAzZ019", first):
+			base = 4
+		case unicode.IsDigit(first):
+			base = 10
+		case unicode.IsUpper(first):
+			base = 26 * 2
+		}
+		if delta < 0 {
+			base *= 2
+		}
+		matches = append(matches, strengthMatch{i, j, "sequence", base * float64(j-i+1)})
+	}
+
+	if len(password) < 2 {
+		return nil
+	}
+
+	i := 0
+	last := int(password[1] - password[0])
+	for k := 2; k < len(password); k++ {
+		delta := int(password[k] - password[k-1])
+		if delta != last {
+			add(i, k-1, last)
+			i = k - 1
+			last = delta
+		}
+	}
+	add(i, len(password)-1, last)
+
+	return matches
+}
+
+// splits of the digits of a date without separators into day, month and year
+var dateSplits = map[int][][2]int{
+	4: {{1, 2}, {2, 3}},
+	5: {{1, 3}, {2, 3}},
+	6: {{1, 2}, {2, 4}, {4, 5}},
+	7: {{1, 3}, {2, 3}, {4, 5}, {4, 6}},
+	8: {{2, 4}, {4, 6}},
+}
+
+var (
+	dateWithSeparator = regexp.MustCompile(`^(\d{1,4})([\s/\\_.-])(\d{1,2})[\s/\\_.-](\d{1,4})$`)
+	yearPattern       = regexp.MustCompile(`^(19|20)\d\d$`)
+)
+
+const referenceYear = 2016
+
+// dateYear returns the year of the date or 0, if the numbers are not a date
+func dateYear(a, b, c int) int {
+	for _, ymd := range [][3]int{{c, b, a}, {c, a, b}, {a, b, c}, {a, c, b}} {
+		year, month, day := ymd[0], ymd[1], ymd[2]
+		if year < 100 {
+			if year > 50 {
+				year += 1900
+			} else {
+				year += 2000
+			}
+		}
+
+		if year >= 1000 && year <= 2050 && month >= 1 && month <= 12 && day >= 1 && day <= 31 {
+			return year
+		}
+	}
+
+	return 0
+}
+
+func yearGuesses(year int) float64 {
+	return math.Max(math.Abs(float64(year-referenceYear)), 20)
+}
+
+func dateMatches(password []rune) []strengthMatch {
+	var matches []strengthMatch
+	for i := range password {
+		for j := i + 3; j < len(password) && j-i < 10; j++ {
+			token := string(password[i : j+1])
+
+			if yearPattern.MatchString(token) {
+				year, _ := strconv.Atoi(token)
+				matches = append(matches, strengthMatch{i, j, "date", yearGuesses(year)})
+			}
+
+			if m := dateWithSeparator.FindStringSubmatch(token); m != nil {
+				a, _ := strconv.Atoi(m[1])
+				b, _ := strconv.Atoi(m[3])
+				c, _ := strconv.Atoi(m[4])
+				if year := dateYear(a, b, c); year != 0 {
+					matches = append(matches, strengthMatch{i, j, "date", yearGuesses(year) * 365 * 4})
+				}
+				continue
+			}
+
+			splits, ok := dateSplits[len(token)]
+			if !ok || strings.Trim(token, "0123456789") != "" {
+				continue
+			}
+			for _, split := range splits {
+				a, _ := strconv.Atoi(token[:split[0]])
+				b, _ := strconv.Atoi(token[split[0]:split[1]])
+				c, _ := strconv.Atoi(token[split[1]:])
+				if year := dateYear(a, b, c); year != 0 {
+					matches = append(matches, strengthMatch{i, j, "date", yearGuesses(year) * 365})
+					break
+				}
+			}
+		}
+	}
+
+	return matches
+}
+
+// the password is analysed up to this length, longer passwords are strong
+// enough anyway and the guesses could overflow
+const maxStrengthLength = 100
+
+// sequences of many patterns are penalised by the guesses for the number of
+// patterns (zxcvbn MIN_GUESSES_BEFORE_GROWING_SEQUENCE)
+const minGuessesBeforeGrowingSequence = 10000
+
+func factorial(n int) float64 {
+	f := 1.0
+	for i := 2; i <= n; i++ {
+		f *= float64(i)
+	}
+	return f
+}
+
+// mostGuessable finds the sequence of matches covering the password, which
+// needs the least guesses, and returns the guesses along with the sequence
+func mostGuessable(password []rune, matches []strengthMatch) (float64, []strengthMatch) {
+	n := len(password)
+	if n == 0 {
+		return 1, nil
+	}
+
+	type step struct {
+		match strengthMatch
+		// product of the guesses of the matches in the sequence
+		pi   float64
+		prev *step
+	}
+
+	// best[k][l] is the best sequence of l matches covering password[:k+1]
+	best := make([]map[int]*step, n)
+	for k := range best {
+		best[k] = make(map[int]*step)
+	}
+
+	guesses := func(m strengthMatch) float64 {
+		min := 1.0
+		if m.j-m.i+1 < n {
+			if m.i == m.j {
+				min = 10
+			} else {
+				min = 50
+			}
+		}
+		return math.Max(m.guesses, min)
+	}
+
+	update := func(m strengthMatch) {
+		g := guesses(m)
+		if m.i == 0 {
+			if s, ok := best[m.j][1]; !ok || g < s.pi {
+				best[m.j][1] = &step{m, g, nil}
+			}
+			return
+		}
+
+		for l, prev := range best[m.i-1] {
+			// consecutive random characters are a single match
+			if m.pattern == "bruteforce" && prev.match.pattern == "bruteforce" {
+				continue
+			}
+			pi := prev.pi * g
+			if s, ok := best[m.j][l+1]; !ok || pi < s.pi {
+				best[m.j][l+1] = &step{m, pi, prev}
+			}
+		}
+	}
+
+	byEnd := make([][]strengthMatch, n)
+	for _, m := range matches {
+		byEnd[m.j] = append(byEnd[m.j], m)
+	}
+
+	for k := 0; k < n; k++ {
+		for _, m := range byEnd[k] {
+			update(m)
+		}
+		for i := 0; i <= k; i++ {
+			update(strengthMatch{i, k, "bruteforce", math.Pow(10, float64(k-i+1))})
+		}
+	}
+
+	var result float64 = math.Inf(1)
+	var last *step
+	for l, s := range best[n-1] {
+		g := factorial(l)*s.pi + math.Pow(minGuessesBeforeGrowingSequence, float64(l-1))
+		if g < result {
+			result, last = g, s
+		}
+	}
+
+	var sequence []strengthMatch
+	for s := last; s != nil; s = s.prev {
+		sequence = append([]strengthMatch{s.match}, sequence...)
+	}
+
+	return result, sequence
+}
+
+var strengthWarnings = map[string]string{
+	"passwords":  "common password",
+	"english":    "dictionary word",
+	"names":      "common name",
+	"surnames":   "common name",
+	"user input": "contains words related to its use",
+	"spatial":    "keyboard pattern",
+	"repeat":     "repeated characters",
+	"sequence":   "character sequence",
+	"date":       "date or year",
+}
+
+// EstimateStrength estimates the strength of the password. Words the password
+// should not be based on, like the user name or the service, can be provided.
+func EstimateStrength(password string, userInputs ...string) *PasswordStrength {
+	strengthOnce.Do(initStrength)
+
+	dictionaries := strengthDictionaries
+	if len(userInputs) > 0 {
+		dictionaries = make(map[string]rankedDictionary)
+		for name, d := range strengthDictionaries {
+			dictionaries[name] = d
+		}
+		dictionaries["user input"] = newRankedDictionary(userInputs)
+	}
+
+	cache := make(map[string]float64)
+	var estimate func([]rune) (float64, []strengthMatch)
+	estimate = func(pw []rune) (float64, []strengthMatch) {
+		var matches []strengthMatch
+		matches = append(matches, dictionaryMatches(pw, dictionaries)...)
+		matches = append(matches, spatialMatches(pw)...)
+		matches = append(matches, sequenceMatches(pw)...)
+		matches = append(matches, dateMatches(pw)...)
+		matches = append(matches, repeatMatches(pw, func(base []rune) float64 {
+			if g, ok := cache[string(base)]; ok {
+				return g
+			}
+			g, _ := estimate(base)
+			cache[string(base)] = g
+			return g
+		})...)
+		return mostGuessable(pw, matches)
+	}
+
+	runes := []rune(password)
+	if len(runes) > maxStrengthLength {
+		runes = runes[:maxStrengthLength]
+	}
+	guesses, sequence := estimate(runes)
+
+	s := &PasswordStrength{Guesses: guesses}
+	for _, threshold := range []float64{1e3, 1e6, 1e8, 1e10} {
+		if guesses >= threshold+5 {
+			s.Score++
+		}
+	}
+
+	if s.Score < 3 {
+		// warn about the longest pattern
+		longest := -1
+		for _, m := range sequence {
+			if warning, ok := strengthWarnings[m.pattern]; ok && m.j-m.i > longest {
+				s.Warning = warning
+				longest = m.j - m.i
+			}
+		}
+		if s.Warning == "" {
+			s.Warning = "too short"
+		}
+	}
+
+	return s
+}
@@ -0,0 +1,66 @@
+package gokey
+
+import (
+	"testing"
+)
+
+func TestEstimateStrength(t *testing.T) {
+	for password, score := range map[string]int{
+		"password":                     0,
+		"drowssap":                     0,
+		"p@ssw0rd":                     0,
+		"qwerty123":                    1,
+		"zxcvbn":                       0,
+		"abcdef":                       0,
+		"aaaaaaaa":                     0,
+		"1991-05-12":                   1,
+		"correcthorsebatterystaple":    4,
+		"super-secret-master-password": 4,
+		"x7$Kp!2qLm#9vZ":               4,
+	} {
+		s := EstimateStrength(password)
+		if s.Score != score {
+			t.Fatalf("password %v has score %v (%v guesses), expected %v", password, s.Score, s.Guesses, score)
+		}
+
+		if (s.Score < 3) != (s.Warning != "") {
+			t.Fatalf("unexpected warning %q for password %v", s.Warning, password)
+		}
+	}
+}
+
+func TestEstimateStrengthUserInputs(t *testing.T) {
+	password := "mybank-website1"
+	if EstimateStrength(password, "mybank").Guesses >= EstimateStrength(password).Guesses {
+		t.Fatal("password based on user input is not weaker")
+	}
+}
+
+func TestStrengthPatterns(t *testing.T) {
+	strengthOnce.Do(initStrength)
+
+	for _, test := range []struct {
+		password string
+		pattern  string
+		matches  func([]rune) []strengthMatch
+	}{
+		{"qwertyuiop", "spatial", spatialMatches},
+		{"7412369", "spatial", spatialMatches},
+		{"13579", "sequence", sequenceMatches},
+		{"zyxwv", "sequence", sequenceMatches},
+		{"24.12.1999", "date", dateMatches},
+		{"19991224", "date", dateMatches},
+		{"2016", "date", dateMatches},
+	} {
+		found := false
+		for _, m := range test.matches([]rune(test.password)) {
+			if m.pattern == test.pattern && m.i == 0 && m.j == len(test.password)-1 {
+				found = true
+			}
+		}
+
+		if !found {
+			t.Fatalf("no %v match for %v", test.pattern, test.password)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package gokey
+
+import (
+	"fmt"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Derivation modes. The same text may be encoded by different sequences of
+// Unicode code points, for example "é" is a single code point when typed on
+// Linux (NFC form) and "e" followed by the combining acute accent on macOS (NFD
+// form), and the derivation uses the bytes of the master password and the
+// realm. Normalized modes produce the same passwords and keys for all encodings
+// of the text. Inputs, which are already in the normalized form, produce the
+// same passwords and keys as with DerivationV0, which is the default.
+const (
+	// DerivationV0 uses the master password and the realm as they are
+	DerivationV0 = iota
+	// DerivationNFC normalizes the master password and the realm to Unicode
+	// NFC form
+	DerivationNFC
+	// DerivationNFKC normalizes the master password and the realm to Unicode
+	// NFKC form, which also replaces compatibility characters like full width
+	// letters and ligatures with their plain equivalents
+	DerivationNFKC
+)
+
+// NormalizeInput returns the master password or the realm for the derivation
+// mode
+func NormalizeInput(s string, mode int) (string, error) {
+	switch mode {
+	case DerivationV0:
+		return s, nil
+	case DerivationNFC:
+		return norm.NFC.String(s), nil
+	case DerivationNFKC:
+		return norm.NFKC.String(s), nil
+	}
+
+	return "", fmt.Errorf("unknown derivation mode %v", mode)
+}
+
+// InputNormalized reports, if the master password or the realm is in Unicode
+// NFC form, so it produces the same passwords and keys with DerivationV0 and
+// DerivationNFC
+func InputNormalized(s string) bool {
+	return norm.NFC.IsNormalString(s)
+}
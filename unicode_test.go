@@ -0,0 +1,60 @@
+package gokey
+
+import "testing"
+
+func TestNormalizeInput(t *testing.T) {
+	nfc := "caf\u00e9"
+	nfd := "cafe\u0301"
+
+	if !InputNormalized(nfc) || InputNormalized(nfd) {
+		t.Fatal("invalid normalization check")
+	}
+
+	pass1, err := GetPass(nfc, "example.com", nil, passSpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pass2, err := GetPass(nfd, "example.com", nil, passSpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pass1 == pass2 {
+		t.Fatal("passwords match for different encodings in legacy mode")
+	}
+
+	for _, mode := range []int{DerivationNFC, DerivationNFKC} {
+		normalized, err := NormalizeInput(nfd, mode)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		pass, err := GetPass(normalized, "example.com", nil, passSpec)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// already normalized inputs produce the same passwords as in legacy mode
+		if pass != pass1 {
+			t.Fatalf("passwords do not match for different encodings in mode %v", mode)
+		}
+	}
+
+	// full width letters are only replaced in NFKC mode
+	for mode, expected := range map[int]string{DerivationV0: "\uff21", DerivationNFC: "\uff21", DerivationNFKC: "A"} {
+		normalized, err := NormalizeInput("\uff21", mode)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if normalized != expected {
+			t.Fatalf("%q normalized to %q in mode %v, expected %q", "\uff21", normalized, mode, expected)
+		}
+	}
+
+	_, err = NormalizeInput(nfc, 3)
+	if err == nil {
+		t.Fatal("normalized input in unknown mode")
+	}
+}
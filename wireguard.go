@@ -0,0 +1,160 @@
+package gokey
+
+import (
+	"crypto"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+)
+
+// WireGuardPeer describes a single node of a WireGuard mesh network
+type WireGuardPeer struct {
+	// Name identifies the node and is mixed into its key derivation, so it
+	// should not change
+	Name string
+	// Address is the node tunnel address in CIDR notation, for example
+	// 10.0.0.1/24
+	Address string
+	// Endpoint is an optional public host:port the node listens on
+	Endpoint string
+}
+
+func validWireGuardName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for _, c := range name {
+		if !strings.ContainsRune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_.", c) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// allowedIP returns the single host network of the peer tunnel address
+func allowedIP(address string) (string, error) {
+	ip, _, err := net.ParseCIDR(address)
+	if err != nil {
+		return "", err
+	}
+
+	if ip.To4() != nil {
+		return ip.String() + "/32", nil
+	}
+
+	return ip.String() + "/128", nil
+}
+
+// GetWireGuardConfigs derives x25519 keys for every peer and preshared keys
+// for every pair of peers and returns complete wg-quick configuration files
+// for all the peers keyed by peer name. Every peer is configured to connect to
+// all the other peers.
+func GetWireGuardConfigs(password, realm string, seed []byte, peers []WireGuardPeer, allowUnsafe bool) (map[string]string, error) {
+	keys := make([]crypto.PrivateKey, len(peers))
+	publicKeys := make([]string, len(peers))
+	allowedIPs := make([]string, len(peers))
+	names := make(map[string]bool)
+
+	for i, peer := range peers {
+		if !validWireGuardName(peer.Name) {
+			return nil, fmt.Errorf("invalid WireGuard peer name %q", peer.Name)
+		}
+
+		if names[peer.Name] {
+			return nil, fmt.Errorf("duplicate WireGuard peer name %q", peer.Name)
+		}
+		names[peer.Name] = true
+
+		var err error
+		allowedIPs[i], err = allowedIP(peer.Address)
+		if err != nil {
+			return nil, err
+		}
+
+		if peer.Endpoint != "" {
+			_, _, err = net.SplitHostPort(peer.Endpoint)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		keys[i], err = GetKey(password, protocolRealm(realm, "wireguard", peer.Name), seed, X25519, allowUnsafe)
+		if err != nil {
+			return nil, err
+		}
+
+		pub, err := x25519PublicKey(keys[i].(x25519PrivateKey))
+		if err != nil {
+			return nil, err
+		}
+		publicKeys[i] = base64.StdEncoding.EncodeToString(pub)
+	}
+
+	configs := make([]strings.Builder, len(peers))
+	for i, peer := range peers {
+		fmt.Fprintf(&configs[i], "[Interface]\n")
+		fmt.Fprintf(&configs[i], "PrivateKey = %s\n", base64.StdEncoding.EncodeToString(keys[i].(x25519PrivateKey)))
+		fmt.Fprintf(&configs[i], "Address = %s\n", peer.Address)
+		if peer.Endpoint != "" {
+			_, port, _ := net.SplitHostPort(peer.Endpoint)
+			fmt.Fprintf(&configs[i], "ListenPort = %s\n", port)
+		}
+	}
+
+	psks := make([][]string, len(peers))
+	for i := range peers {
+		psks[i] = make([]string, len(peers))
+	}
+
+	psk := make([]byte, 32)
+	for i := range peers {
+		for j := i + 1; j < len(peers); j++ {
+			// the preshared key of a pair does not depend on the peer order
+			a, b := peers[i].Name, peers[j].Name
+			if a > b {
+				a, b = b, a
+			}
+
+			raw, err := GetRaw(password, protocolRealm(realm, "wireguard-psk", a, b), seed, allowUnsafe)
+			if err != nil {
+				return nil, err
+			}
+
+			_, err = io.ReadFull(raw, psk)
+			if err != nil {
+				return nil, err
+			}
+
+			psks[i][j] = base64.StdEncoding.EncodeToString(psk)
+			psks[j][i] = psks[i][j]
+		}
+	}
+
+	for i := range peers {
+		for j, peer := range peers {
+			if i == j {
+				continue
+			}
+
+			fmt.Fprintf(&configs[i], "\n[Peer]\n")
+			fmt.Fprintf(&configs[i], "# %s\n", peer.Name)
+			fmt.Fprintf(&configs[i], "PublicKey = %s\n", publicKeys[j])
+			fmt.Fprintf(&configs[i], "PresharedKey = %s\n", psks[i][j])
+			fmt.Fprintf(&configs[i], "AllowedIPs = %s\n", allowedIPs[j])
+			if peer.Endpoint != "" {
+				fmt.Fprintf(&configs[i], "Endpoint = %s\n", peer.Endpoint)
+			}
+		}
+	}
+
+	result := make(map[string]string)
+	for i, peer := range peers {
+		result[peer.Name] = configs[i].String()
+	}
+
+	return result, nil
+}
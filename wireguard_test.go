@@ -0,0 +1,120 @@
+package gokey
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+var wireGuardPeers = []WireGuardPeer{
+	{Name: "alpha", Address: "10.0.0.1/24", Endpoint: "alpha.example.com:51820"},
+	{Name: "beta", Address: "10.0.0.2/24", Endpoint: "beta.example.com:51821"},
+	{Name: "gamma", Address: "fd00::3/64"},
+}
+
+// wireGuardValue returns the value of the key in the section following the
+// section header
+func wireGuardValue(t *testing.T, config, header, key string) string {
+	start := strings.Index(config, header)
+	if start < 0 {
+		t.Fatalf("no %q section in config", header)
+	}
+
+	for _, line := range strings.Split(config[start:], "\n") {
+		if strings.HasPrefix(line, key+" = ") {
+			return strings.TrimPrefix(line, key+" = ")
+		}
+	}
+
+	t.Fatalf("no %v in %q section", key, header)
+	return ""
+}
+
+func TestWireGuardConfigs(t *testing.T) {
+	seed, err := GenerateEncryptedKeySeed("pass1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	configs, err := GetWireGuardConfigs("pass1", "mesh", seed, wireGuardPeers, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, peer := range wireGuardPeers {
+		privKey, err := base64.StdEncoding.DecodeString(wireGuardValue(t, configs[peer.Name], "[Interface]", "PrivateKey"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		pub, err := x25519PublicKey(x25519PrivateKey(privKey))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, other := range wireGuardPeers {
+			if other.Name == peer.Name {
+				continue
+			}
+
+			if wireGuardValue(t, configs[other.Name], "# "+peer.Name, "PublicKey") != base64.StdEncoding.EncodeToString(pub) {
+				t.Fatalf("public key of %v does not match its private key", peer.Name)
+			}
+
+			if wireGuardValue(t, configs[other.Name], "# "+peer.Name, "PresharedKey") != wireGuardValue(t, configs[peer.Name], "# "+other.Name, "PresharedKey") {
+				t.Fatalf("preshared keys do not match for %v and %v", peer.Name, other.Name)
+			}
+		}
+	}
+
+	// the keys of the peers are not derived for any realm typed by the user
+	key, err := GetKey("pass1", "mesh-wireguard(alpha)", seed, X25519, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if wireGuardValue(t, configs["alpha"], "[Interface]", "PrivateKey") == base64.StdEncoding.EncodeToString(key.(x25519PrivateKey)) {
+		t.Fatal("WireGuard key matches x25519 key for a crafted realm")
+	}
+
+	if wireGuardValue(t, configs["alpha"], "# gamma", "AllowedIPs") != "fd00::3/128" {
+		t.Fatal("invalid allowed IPs for IPv6 peer")
+	}
+
+	if wireGuardValue(t, configs["beta"], "[Interface]", "ListenPort") != "51821" {
+		t.Fatal("invalid listen port")
+	}
+
+	configsRetry, err := GetWireGuardConfigs("pass1", "mesh", seed, wireGuardPeers[:2], false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if wireGuardValue(t, configs["alpha"], "[Interface]", "PrivateKey") != wireGuardValue(t, configsRetry["alpha"], "[Interface]", "PrivateKey") {
+		t.Fatal("keys with same invocation options do not match")
+	}
+
+	_, err = GetWireGuardConfigs("pass1", "mesh", seed, wireGuardPeers, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = GetWireGuardConfigs("pass1", "mesh", nil, wireGuardPeers, false)
+	if err == nil {
+		t.Fatal("allowed unsafe key generation")
+	}
+}
+
+func TestWireGuardInvalidPeers(t *testing.T) {
+	for _, peers := range [][]WireGuardPeer{
+		{{Name: "a,b", Address: "10.0.0.1/24"}},
+		{{Name: "a", Address: "10.0.0.1"}},
+		{{Name: "a", Address: "10.0.0.1/24", Endpoint: "a.example.com"}},
+		{{Name: "a", Address: "10.0.0.1/24"}, {Name: "a", Address: "10.0.0.2/24"}},
+	} {
+		_, err := GetWireGuardConfigs("pass1", "mesh", nil, peers, true)
+		if err == nil {
+			t.Fatalf("allowed invalid peers %v", peers)
+		}
+	}
+}